@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/exaring/release-cli/pkg/release"
+	"github.com/exaring/release-cli/pkg/version"
+	"github.com/urfave/cli"
+)
+
+var branchesCommand = cli.Command{
+	Name:   "branches",
+	Usage:  "list the maintenance branches of the release lines and their latest versions.",
+	Flags:  []cli.Flag{outputFlag},
+	Action: branches,
+}
+
+// branchInfo is the JSON representation of a maintenance branch.
+type branchInfo struct {
+	Branch  string       `json:"branch"`
+	Line    string       `json:"line"`
+	Version *versionInfo `json:"version,omitempty"`
+}
+
+func branches(ctx *cli.Context) error {
+	format := tagFormat(ctx)
+	repo, err := openRepository(ctx)
+	if err != nil {
+		return err
+	}
+
+	template, err := branchTemplate(ctx)
+	if err != nil {
+		return err
+	}
+
+	names, err := repo.Branches()
+	if err != nil {
+		return fmt.Errorf("failed to list the branches: %w", err)
+	}
+
+	versions := release.Versions(repo, format, "")
+
+	var lines = make([]string, 0, len(names))
+	var infos = make([]branchInfo, 0, len(names))
+	for _, name := range names {
+		line, ok := version.TemplateLine(template, name)
+		if !ok {
+			continue
+		}
+
+		info := branchInfo{Branch: name, Line: line.String()}
+		text := fmt.Sprintf("%v %v", name, "-")
+		if lineVersions := filterLine(versions, line); len(lineVersions) > 0 {
+			latest := newVersionInfo(format, lineVersions[len(lineVersions)-1])
+			info.Version = &latest
+			text = fmt.Sprintf("%v %v", name, latest.Version)
+		}
+
+		infos = append(infos, info)
+		lines = append(lines, text)
+	}
+
+	return printOutput(ctx, strings.Join(lines, "\n"), infos)
+}
+
+// branchTemplate returns the validated name template of maintenance branches.
+func branchTemplate(ctx *cli.Context) (string, error) {
+	template := ctx.GlobalString("branch-template")
+	if template == "" {
+		return version.DefaultBranchTemplate, nil
+	}
+
+	if err := version.ValidateBranchTemplate(template); err != nil {
+		return "", err
+	}
+	return template, nil
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/exaring/release-cli/pkg/config"
+	"github.com/exaring/release-cli/pkg/version"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+)
+
+var (
+	// settings is the merged configuration of the user and project configuration files.
+	settings config.Config
+	// settingFiles lists the read configuration files in the order of their precedence.
+	settingFiles []string
+)
+
+var configCommand = cli.Command{
+	Name:  "config",
+	Usage: "validate and show the configuration of the .release.yaml or .release.toml files.",
+	Subcommands: []cli.Command{
+		{
+			Name:      "validate",
+			Usage:     "validate the given configuration file or the user and project configuration files.",
+			ArgsUsage: "[<file>]",
+			Action:    validateConfig,
+		},
+		{
+			Name:   "show",
+			Usage:  "print the effective settings of the flags, environment variables and configuration files.",
+			Action: showConfig,
+		},
+		{
+			Name:  "schema",
+			Usage: "print the JSON schema of the configuration files.",
+			Action: func(ctx *cli.Context) error {
+				_, err := fmt.Fprint(ctx.App.Writer, config.Schema)
+				return err
+			},
+		},
+	},
+}
+
+// loadSettings reads the configuration files and applies their values to all global flags which are neither set by
+// the command line nor by environment variables. Therefore the precedence is flags > env > project config > user config.
+func loadSettings(ctx *cli.Context) error {
+	currentPath, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	settings, settingFiles, err = config.Load(currentPath)
+	if err != nil {
+		return fmt.Errorf("failed to load the configuration: %w", err)
+	}
+
+	var values = map[string]string{
+		"branch":           settings.Branch,
+		"remote":           settings.Remote,
+		"backend":          settings.Backend,
+		"branch-template":  settings.BranchTemplate,
+		"gen-version-file": settings.GenVersionFile,
+		"forge":            settings.Forge.Provider,
+		"forge-url":        settings.Forge.URL,
+		"tracker":          settings.Tracker.Provider,
+		"tracker-url":      settings.Tracker.URL,
+		"output":           settings.Output,
+		"output-file":      settings.OutputFile,
+		"log":              settings.Log,
+		"pre-label":        settings.Tag.PreLabel,
+		"api-check":        boolValue(settings.Checks.API),
+		"zip-check":        boolValue(settings.Checks.Zip),
+		"floating":         boolValue(settings.Floating.Aliases),
+		"floating-latest":  boolValue(settings.Floating.Latest),
+		"remote-lock":      boolValue(settings.RemoteLock),
+	}
+	if settings.Retry != nil {
+		values["retry"] = strconv.Itoa(*settings.Retry)
+	}
+	// the prefix is applied separately, because an empty prefix is a valid setting
+	if settings.Tag.Prefix != nil {
+		if !ctx.GlobalIsSet("tag-prefix") {
+			if err := ctx.GlobalSet("tag-prefix", *settings.Tag.Prefix); err != nil {
+				return err
+			}
+		}
+	}
+
+	for name, value := range values {
+		if value == "" || ctx.GlobalIsSet(name) {
+			continue
+		}
+		if err := ctx.GlobalSet(name, value); err != nil {
+			return fmt.Errorf("failed to apply the configuration of %v: %w", name, err)
+		}
+	}
+
+	if err := tagFormat(ctx).Validate(); err != nil {
+		return err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"Files": settingFiles,
+	}).Debug("Load the configuration")
+
+	return nil
+}
+
+// boolValue returns the flag value of a boolean setting. Unset and disabled settings are empty, because a set boolean
+// flag is always enabled. A disabled setting of the project configuration overrides an enabled user setting already
+// while merging.
+func boolValue(b *bool) string {
+	if !config.Enabled(b) {
+		return ""
+	}
+	return strconv.FormatBool(*b)
+}
+
+func validateConfig(ctx *cli.Context) error {
+	if ctx.NArg() > 0 {
+		if _, err := config.ReadFile(ctx.Args().First()); err != nil {
+			return err
+		}
+		_, err := fmt.Fprintf(ctx.App.Writer, "%v: valid\n", ctx.Args().First())
+		return err
+	}
+
+	currentPath, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	var files []string
+	for _, file := range []string{config.UserFile(), config.FindFile(currentPath)} {
+		if file == "" {
+			continue
+		}
+		if _, err := config.ReadFile(file); err != nil {
+			return err
+		}
+		files = append(files, file)
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no configuration file found")
+	}
+
+	for _, file := range files {
+		if _, err := fmt.Fprintf(ctx.App.Writer, "%v: valid\n", file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func showConfig(ctx *cli.Context) error {
+	effective := settings
+	format := tagFormat(ctx)
+	effective.Tag = config.Tag{Prefix: &format.Prefix, PreLabel: format.PreLabel}
+	effective.Branch = ctx.GlobalString("branch")
+	effective.Remote = ctx.GlobalString("remote")
+	apiCheck, zipCheck := ctx.GlobalIsSet("api-check"), ctx.GlobalIsSet("zip-check")
+	effective.Checks.API, effective.Checks.Zip = &apiCheck, &zipCheck
+	effective.Output = ctx.GlobalString("output")
+	effective.OutputFile = ctx.GlobalString("output-file")
+	effective.Log = ctx.GlobalString("log")
+
+	data, err := config.Marshal(effective)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range settingFiles {
+		if _, err := fmt.Fprintf(ctx.App.Writer, "# %v\n", file); err != nil {
+			return err
+		}
+	}
+	_, err = ctx.App.Writer.Write(data)
+	return err
+}
+
+// tagFormat returns the notation of the version tags of the tag-prefix and pre-label flags. The channel of the branch
+// policy replaces the pre-release label of new releases, see release.Releaser.Format.
+func tagFormat(ctx *cli.Context) version.Format {
+	format := version.DefaultFormat
+	if ctx.GlobalIsSet("tag-prefix") {
+		format.Prefix = ctx.GlobalString("tag-prefix")
+	}
+	if ctx.GlobalIsSet("pre-label") {
+		format.PreLabel = ctx.GlobalString("pre-label")
+	}
+	return format
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/exaring/release-cli/pkg/release"
+	"github.com/exaring/release-cli/pkg/version"
+	"github.com/urfave/cli"
+)
+
+var describeCommand = cli.Command{
+	Name:  "describe",
+	Usage: "print the version of the current commit as Go pseudo-version or in the notation of git describe.",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "format",
+			Value: "pseudo",
+			Usage: "the notation of the version: pseudo (v1.2.4-0.20200102150405-abcdef123456) or describe (v1.2.3-5-gabcdef1).",
+		},
+		outputFlag,
+	},
+	Action: describe,
+}
+
+func describe(ctx *cli.Context) error {
+	format := ctx.String("format")
+	if format != "pseudo" && format != "describe" {
+		return fmt.Errorf("unknown format %q", format)
+	}
+
+	repo, err := openRepository(ctx)
+	if err != nil {
+		return err
+	}
+
+	v, err := Describe(repo, tagFormat(ctx), ctx.GlobalString("branch"), format == "pseudo")
+	if err != nil {
+		return err
+	}
+
+	return printOutput(ctx, v, struct {
+		Version string `json:"version"`
+	}{
+		Version: v,
+	})
+}
+
+// Describe returns the version of the current HEAD as Go pseudo-version or in the notation of git describe. The base
+// version is the latest tag of the format reachable from HEAD, which is limited to the tags of the given branch if it's
+// not empty. A tagged HEAD is described by its tag only.
+func Describe(vc Repository, format version.Format, branchName string, pseudo bool) (string, error) {
+	var tags = vc.ReachableTags("HEAD")
+	if branchName != "" {
+		tags = vc.BranchTags(branchName)
+	}
+
+	versions := release.ParseVersions(format, tags)
+
+	var base version.Version
+	var tag string
+	if len(versions) > 0 {
+		base = versions[len(versions)-1]
+		tag = format.Tag(base)
+	}
+
+	commits, err := vc.Commits(tag, "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("failed to list the commits since %v: %w", tag, err)
+	}
+	if len(commits) == 0 {
+		return tag, nil
+	}
+
+	if pseudo {
+		return version.Pseudo(format, base, commits[0].When, commits[0].Hash), nil
+	}
+	return version.Describe(format, tag, len(commits), commits[0].Hash), nil
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/exaring/release-cli/pkg/changelog"
+	"github.com/exaring/release-cli/pkg/forge"
+	"github.com/exaring/release-cli/pkg/release"
+	"github.com/exaring/release-cli/pkg/version"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+)
+
+var forgeCommand = cli.Command{
+	Name:      "forge",
+	Usage:     "create or update the release entry of a version on the forge. Defaults to the latest version.",
+	ArgsUsage: "[<version>]",
+	Action:    forgeRelease,
+}
+
+func forgeRelease(ctx *cli.Context) error {
+	if ctx.NArg() > 1 {
+		return fmt.Errorf("expected at most one version, got %d arguments", ctx.NArg())
+	}
+	if !ctx.GlobalIsSet("forge") {
+		return fmt.Errorf("the forge isn't configured, set --forge or forge.provider")
+	}
+
+	git, err := openRepository(ctx)
+	if err != nil {
+		return err
+	}
+
+	format := tagFormat(ctx)
+	var v version.Version
+	if ctx.NArg() == 0 {
+		line, err := releaseLine(ctx, git)
+		if err != nil {
+			return err
+		}
+		if v, err = release.LatestVersion(git, format, ctx.GlobalString("branch"), line); err != nil {
+			return err
+		}
+	} else if v, err = format.Parse(ctx.Args().First()); err != nil {
+		return err
+	}
+
+	if ctx.GlobalIsSet("dry") {
+		logrus.WithFields(logrus.Fields{
+			"Tag": format.Tag(v),
+		}).Info("Don't publish the forge release, because of the dry-run mode")
+		return nil
+	}
+
+	c, stop := signalContext()
+	defer stop()
+	return PublishForgeRelease(c, ctx, git, format, v, "")
+}
+
+// publishForge publishes the forge release of the new version if a forge is configured. The tag is already pushed, so
+// the errors are only logged. The dry-run mode doesn't publish the release.
+func publishForge(c context.Context, ctx *cli.Context, git Client, format version.Format, v version.Version, notes string) {
+	if !ctx.GlobalIsSet("forge") || ctx.GlobalIsSet("dry") {
+		return
+	}
+	if err := PublishForgeRelease(c, ctx, git, format, v, notes); err != nil {
+		logrus.WithError(err).Errorf("Couldn't publish the forge release, retry with \"release forge %v\"",
+			format.Tag(v))
+	}
+}
+
+// PublishForgeRelease creates or updates the release entry of the version on the configured forge. Without the given
+// notes, the notes are taken from the section of the version in the changelog file or else from the commits since the
+// previous version.
+func PublishForgeRelease(c context.Context, ctx *cli.Context, git Client, format version.Format, v version.Version, notes string) error {
+	logger := logrus.StandardLogger()
+
+	opts := forge.Options{
+		BaseURL:    ctx.GlobalString("forge-url"),
+		Token:      forgeAPIToken(ctx),
+		Repository: settings.Forge.Repository,
+	}
+	if opts.Repository == "" {
+		remoteURL, err := git.RemoteURL()
+		if err != nil {
+			return fmt.Errorf("failed to detect the remote URL: %w", err)
+		}
+		if opts.Repository, err = forge.RepositoryPath(remoteURL); err != nil {
+			return err
+		}
+	}
+	provider, err := forge.New(ctx.GlobalString("forge"), opts)
+	if err != nil {
+		return err
+	}
+
+	if notes == "" {
+		if notes, err = releaseNotes(git, format, v); err != nil {
+			return err
+		}
+	}
+	assets, err := releaseAssets(git)
+	if err != nil {
+		return err
+	}
+
+	url, err := provider.Publish(c, forge.Release{
+		Tag:        format.Tag(v),
+		Name:       format.Tag(v),
+		Notes:      notes,
+		Prerelease: v.IsReleaseCandidate(),
+		Assets:     assets,
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.WithFields(logrus.Fields{
+		"URL":    url,
+		"Assets": len(assets),
+	}).Info("Publish the forge release")
+	return nil
+}
+
+// forgeAPIToken returns the token of the forge-token flag or of the usual environment variable of the forge like
+// GITHUB_TOKEN.
+func forgeAPIToken(ctx *cli.Context) string {
+	if ctx.GlobalIsSet("forge-token") {
+		return ctx.GlobalString("forge-token")
+	}
+	return os.Getenv(strings.ToUpper(ctx.GlobalString("forge")) + "_TOKEN")
+}
+
+// releaseNotes returns the section of the version in the changelog file or the commit list since the previous version.
+func releaseNotes(git Client, format version.Format, v version.Version) (string, error) {
+	if settings.Changelog.File != "" {
+		data, err := ioutil.ReadFile(filepath.Join(git.Path(), settings.Changelog.File))
+		if err != nil {
+			return "", fmt.Errorf("failed to read the changelog: %w", err)
+		}
+		if notes, ok := changelog.Section(data, release.FileVersion(format, v)); ok {
+			return notes, nil
+		}
+		logrus.WithFields(logrus.Fields{
+			"File": settings.Changelog.File,
+		}).Warnf("The changelog has no section of %v, the notes are taken from the commits", format.Tag(v))
+	}
+
+	commits, err := git.Commits(previousRelease(git, format, v), format.Tag(v))
+	if err != nil {
+		return "", fmt.Errorf("failed to list the commits of the release: %w", err)
+	}
+	return changelog.FromCommits(commits), nil
+}
+
+// previousRelease returns the tag of the version before the given one or an empty string for the first release. The
+// previous version of final releases is the previous final release.
+func previousRelease(git Client, format version.Format, v version.Version) string {
+	versions := release.Versions(git, format, "")
+
+	var previous string
+	for _, e := range versions {
+		if version.Compare(e, v) < 0 && (v.IsReleaseCandidate() || !e.IsReleaseCandidate()) {
+			previous = format.Tag(e)
+		}
+	}
+	return previous
+}
+
+// releaseAssets returns the files of the asset patterns.
+func releaseAssets(git Client) ([]string, error) {
+	var assets []string
+	for _, pattern := range settings.Forge.Assets {
+		files, err := filepath.Glob(filepath.Join(git.Path(), pattern))
+		if err != nil {
+			return nil, fmt.Errorf("invalid asset pattern %q: %w", pattern, err)
+		}
+		if len(files) == 0 {
+			return nil, fmt.Errorf("no asset matches the pattern %q", pattern)
+		}
+		assets = append(assets, files...)
+	}
+	return assets, nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/exaring/release-cli/pkg/fragment"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+)
+
+var fragmentsCommand = cli.Command{
+	Name:  "fragments",
+	Usage: "manage the change fragments in " + fragment.Dir + ", which determine the increased version part and the release notes.",
+	Subcommands: []cli.Command{
+		{
+			Name:  "new",
+			Usage: "create a change fragment. The bump type and the description are prompted unless they are given by flags.",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "bump",
+					Usage: "the bump type of the change: major, minor or patch.",
+				},
+				cli.StringFlag{
+					Name:  "m, message",
+					Usage: "the Markdown description of the change.",
+				},
+			},
+			Action: newFragment,
+		},
+	},
+}
+
+func newFragment(ctx *cli.Context) error {
+	git, err := openRepository(ctx)
+	if err != nil {
+		return err
+	}
+
+	in := bufio.NewReader(os.Stdin)
+	bump, body := ctx.String("bump"), ctx.String("message")
+	if !ctx.IsSet("bump") {
+		if bump, err = prompt(in, ctx.App.Writer, fmt.Sprintf("Bump type %v [%v]: ", fragment.Bumps, fragment.Patch)); err != nil {
+			return err
+		}
+		if bump == "" {
+			bump = fragment.Patch
+		}
+	}
+	if !ctx.IsSet("message") {
+		fmt.Fprintln(ctx.App.Writer, "Description (Markdown, end with an empty line):")
+		var lines []string
+		for {
+			line, err := prompt(in, ctx.App.Writer, "")
+			if err != nil {
+				return err
+			}
+			if line == "" {
+				break
+			}
+			lines = append(lines, line)
+		}
+		body = strings.Join(lines, "\n")
+	}
+
+	path, data, err := fragment.New(bump, body, time.Now())
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Join(git.Path(), fragment.Dir), 0755); err != nil {
+		return fmt.Errorf("failed to create the fragment directory: %w", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(git.Path(), path), data, 0644); err != nil {
+		return fmt.Errorf("failed to write the fragment: %w", err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"File": path,
+		"Bump": bump,
+	}).Info("Create the change fragment")
+	return nil
+}
+
+// prompt writes the question and returns the trimmed answer. The end of the input is an empty answer.
+func prompt(in *bufio.Reader, out io.Writer, question string) (string, error) {
+	fmt.Fprint(out, question)
+	line, err := in.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("failed to read the answer: %w", err)
+	}
+	return strings.TrimSpace(line), nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/exaring/release-cli/pkg/config"
+	"github.com/exaring/release-cli/pkg/hook"
+	"github.com/exaring/release-cli/pkg/output"
+	"github.com/exaring/release-cli/pkg/release"
+	"github.com/exaring/release-cli/pkg/transaction"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+)
+
+// releaseHooks runs the hooks of the configuration with the details of the release. The output of the hooks is
+// written to stderr to keep stdout clean for the machine-readable output. The dry-run mode doesn't run any hook.
+type releaseHooks struct {
+	runner *hook.Runner
+	// release are the details of the release, which are completed while the release proceeds.
+	release output.Release
+}
+
+// newHooks returns the hooks of the repository.
+func newHooks(ctx *cli.Context, git Client) *releaseHooks {
+	h := &releaseHooks{
+		runner:  &hook.Runner{Dir: git.Path(), Output: os.Stderr},
+		release: output.Release{DryRun: ctx.GlobalIsSet("dry")},
+	}
+	if !h.release.DryRun {
+		h.runner.Commands = settings.Hooks
+	}
+	if remote, err := git.RemoteURL(); err == nil {
+		h.release.Remote = output.RedactURL(remote)
+	}
+	return h
+}
+
+// Update completes the details of the release with the planned or published release.
+func (h *releaseHooks) Update(result *release.Result) {
+	h.release.PreviousVersion = result.Format.Version(result.Previous)
+	h.release.Version = result.Format.Version(result.Next)
+	h.release.Tag = result.Tag
+	h.release.Commit = result.Commit
+	h.release.Bump = result.Bump()
+	h.release.Branch = result.Branch
+	h.release.LDFlags = result.LDFlags
+	h.release.ModuleHash = result.ModuleHash
+}
+
+// Run runs the hook. A failing hook aborts the release.
+func (h *releaseHooks) Run(ctx context.Context, name string) error {
+	logrus.WithFields(logrus.Fields{
+		"Hook": name,
+	}).Debug("Run the hook")
+	return h.runner.Run(ctx, name, h.release)
+}
+
+// Attach runs the hook steps of the transaction.
+func (h *releaseHooks) Attach(tx *transaction.Transaction) {
+	tx.SetHook(h.Run)
+}
+
+// Pushed runs the post-push hook. The release is already published, so the errors are only logged.
+func (h *releaseHooks) Pushed(ctx context.Context) {
+	if err := h.Run(ctx, config.HookPostPush); err != nil {
+		logrus.WithError(err).Error("The post-push hook failed")
+	}
+}
+
+// Failed runs the on-failure hook with the error in RELEASE_ERROR if the release failed. It runs even if the release
+// was interrupted, so it doesn't use the canceled context of the command.
+func (h *releaseHooks) Failed(err error) {
+	if err == nil {
+		return
+	}
+	if err := h.runner.Run(context.Background(), config.HookOnFailure, h.release, "RELEASE_ERROR="+err.Error()); err != nil {
+		logrus.WithError(err).Error("The on-failure hook failed")
+	}
+}
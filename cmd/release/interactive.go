@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/exaring/release-cli/pkg/config"
+	"github.com/exaring/release-cli/pkg/fragment"
+	"github.com/exaring/release-cli/pkg/release"
+	"github.com/exaring/release-cli/pkg/repository"
+	"github.com/exaring/release-cli/pkg/tui"
+	"github.com/exaring/release-cli/pkg/version"
+	"github.com/urfave/cli"
+)
+
+// maxPreviewCommits limits the commits of the interactive preview.
+const maxPreviewCommits = 15
+
+// releaseOption is a version part of the interactive mode with its resulting version.
+type releaseOption struct {
+	part string
+	next version.Version
+	// err is the error of the branch policy, which refuses the version.
+	err error
+}
+
+// chooseRelease shows the latest version, the commits since the latest version and the resulting versions of all
+// version parts in the tag format. The chosen version part is set as flag, so that the release proceeds like a
+// non-interactive one. It returns the tag message, which the user edited, or an empty message for a lightweight tag.
+// The interactive mode requires a terminal and refuses to run in pipelines.
+func chooseRelease(ctx *cli.Context, repo Repository, format version.Format, policy config.Branch, line *version.Line, fragments []fragment.Fragment) (string, error) {
+	for _, part := range []string{"major", "minor", "patch", "pre"} {
+		if ctx.IsSet(part) {
+			return "", fmt.Errorf("the interactive mode chooses the version part, remove the flag --%v", part)
+		}
+	}
+	term := tui.Terminal{In: os.Stdin, Out: os.Stderr}
+	if err := term.Check(); err != nil {
+		return "", fmt.Errorf("the interactive mode requires a terminal, set the version part by --major, --minor, "+
+			"--patch or --pre instead: %w", err)
+	}
+
+	latest, err := release.LatestVersion(repo, format, ctx.String("branch"), line)
+	if err != nil {
+		return "", err
+	}
+	from := format.Tag(latest)
+	if _, err := repo.ResolveRevision(from); err != nil {
+		from = ""
+	}
+	commits, err := repo.Commits(from, "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("failed to list the commits since the latest version: %w", err)
+	}
+
+	options := releaseOptions(latest, policy)
+	menu := tui.Menu{
+		Title:    "Choose the release:",
+		Selected: 2,
+		Help:     "up/down: select, enter: release, e: edit the tag message, q: cancel",
+	}
+	for i, o := range options {
+		detail := fmt.Sprintf("%v -> %v", format.Tag(latest), format.Tag(o.next))
+		if o.err != nil {
+			detail += fmt.Sprintf(" (refused: %v)", o.err)
+		}
+		menu.Options = append(menu.Options, tui.Option{Label: o.part, Detail: detail})
+		if o.part == fragment.Bump(fragments) {
+			menu.Selected = i
+		}
+	}
+	writePreview(term.Out, from, commits)
+
+	in := bufio.NewReader(term.In)
+	restore, err := term.Raw()
+	if err != nil {
+		return "", err
+	}
+	choice, key, err := tui.Choose(in, term.Out, menu)
+	restore()
+	if err != nil {
+		return "", err
+	}
+	chosen := options[choice]
+	if chosen.err != nil {
+		return "", chosen.err
+	}
+
+	var message string
+	if key == tui.Edit {
+		if message, err = tui.EditText(tagMessage(format.Tag(chosen.next), commits)); err != nil {
+			return "", err
+		}
+	}
+
+	if restore, err = term.Raw(); err != nil {
+		return "", err
+	}
+	ok, err := tui.Confirm(in, term.Out, fmt.Sprintf("Release %v?", format.Tag(chosen.next)))
+	restore()
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", tui.ErrCanceled
+	}
+
+	return message, ctx.Set(chosen.part, "true")
+}
+
+// releaseOptions returns the resulting versions of the version parts, which are checked by the branch policy.
+func releaseOptions(latest version.Version, policy config.Branch) []releaseOption {
+	var options []releaseOption
+	for _, part := range []string{"major", "minor", "patch", "pre"} {
+		next := make(version.Version, len(latest))
+		copy(next, latest)
+		next.Increase(part == "major", part == "minor", part == "patch", part == "pre" || policy.Channel != "")
+		options = append(options, releaseOption{part: part, next: next, err: policy.Check(latest, next)})
+	}
+	return options
+}
+
+// writePreview writes the tag of the latest version and the commits since the latest version.
+func writePreview(out io.Writer, from string, commits []repository.Commit) {
+	if from == "" {
+		fmt.Fprintf(out, "There is no version yet, %d commits\n", len(commits))
+	} else {
+		fmt.Fprintf(out, "Latest version %v, %d commits since then\n", from, len(commits))
+	}
+	for i, c := range commits {
+		if i == maxPreviewCommits {
+			fmt.Fprintf(out, "  ... and %d more\n", len(commits)-maxPreviewCommits)
+			break
+		}
+		fmt.Fprintf(out, "  %.7v %v\n", c.Hash, subject(c.Message))
+	}
+	fmt.Fprintln(out)
+}
+
+// tagMessage returns the proposed message of the annotated tag with the subjects of the commits.
+func tagMessage(tag string, commits []repository.Commit) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Release %v\n\n", tag)
+	for _, c := range commits {
+		fmt.Fprintf(&b, "- %v\n", subject(c.Message))
+	}
+	b.WriteString("\n# The message of the annotated tag. Lines starting with # are ignored.\n" +
+		"# An empty message creates a lightweight tag.\n")
+	return b.String()
+}
+
+// subject returns the first line of the commit message.
+func subject(message string) string {
+	return strings.TrimSpace(strings.SplitN(message, "\n", 2)[0])
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"github.com/exaring/release-cli/pkg/version"
+	"github.com/urfave/cli"
+)
+
+// releaseLine returns the release line of the line flag or of the current maintenance branch, which matches the branch
+// template. It returns nil if the release isn't limited to a line.
+func releaseLine(ctx *cli.Context, vc Repository) (*version.Line, error) {
+	if ctx.GlobalIsSet("line") {
+		line, err := version.ParseLine(ctx.GlobalString("line"))
+		if err != nil {
+			return nil, err
+		}
+		return &line, nil
+	}
+
+	branch, err := vc.CurrentBranch()
+	if err != nil {
+		// a detached HEAD isn't a maintenance branch
+		return nil, nil
+	}
+
+	template, err := branchTemplate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if line, ok := version.TemplateLine(template, branch); ok {
+		return &line, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/exaring/release-cli/pkg/gomod"
+	"github.com/exaring/release-cli/pkg/release"
+	"github.com/exaring/release-cli/pkg/version"
+	"github.com/urfave/cli"
+)
+
+var listCommand = cli.Command{
+	Name:  "list",
+	Usage: "list all version tags in ascending order. Retracted versions are marked.",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "line",
+			Usage: "only list the versions of the given release line, e.g. v2 or v2.4.",
+		},
+		outputFlag,
+	},
+	Action: list,
+}
+
+func list(ctx *cli.Context) error {
+	format := tagFormat(ctx)
+	repo, err := openRepository(ctx)
+	if err != nil {
+		return err
+	}
+
+	versions := release.Versions(repo, format, ctx.GlobalString("branch"))
+
+	if ctx.IsSet("line") {
+		line, err := version.ParseLine(ctx.String("line"))
+		if err != nil {
+			return err
+		}
+		versions = filterLine(versions, line)
+	}
+
+	var retractions []gomod.Retraction
+	if data, err := ioutil.ReadFile(filepath.Join(repo.Path(), gomod.FileName)); err == nil {
+		if retractions, err = gomod.Retractions(data); err != nil {
+			return fmt.Errorf("failed to read the retractions of the go.mod file: %w", err)
+		}
+	}
+
+	var lines = make([]string, 0, len(versions))
+	var infos = make([]versionInfo, 0, len(versions))
+	for _, v := range versions {
+		info := newVersionInfo(format, v)
+		for _, r := range retractions {
+			if r.Covers(info.Version) {
+				info.Retracted, info.Rationale = true, r.Rationale
+				break
+			}
+		}
+		infos = append(infos, info)
+
+		line := info.Version
+		switch {
+		case info.Retracted && info.Rationale != "":
+			line += fmt.Sprintf(" (retracted: %v)", info.Rationale)
+		case info.Retracted:
+			line += " (retracted)"
+		}
+		lines = append(lines, line)
+	}
+
+	return printOutput(ctx, strings.Join(lines, "\n"), infos)
+}
+
+// filterLine returns the versions which belong to the release line.
+func filterLine(versions version.Versions, line version.Line) version.Versions {
+	var filtered version.Versions
+	for _, v := range versions {
+		if line.Contains(v) {
+			filtered = append(filtered, v)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/exaring/release-cli/pkg/lock"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+)
+
+// lockName is the file name of the local release lock in the git directory.
+const lockName = "release.lock"
+
+// acquireLocks acquires the local lock file in the git directory and the lock reference on the remote if the
+// remote-lock flag is set. The returned function releases the locks even if the release was interrupted, so it doesn't
+// use the context of the command. The dry-run mode doesn't lock.
+func acquireLocks(c context.Context, ctx *cli.Context, git Client) (func(), error) {
+	logger := logrus.StandardLogger()
+	if ctx.GlobalIsSet("dry") {
+		return func() {}, nil
+	}
+
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown host"
+	}
+	owner := fmt.Sprintf("%v (pid %d)", host, os.Getpid())
+
+	file, err := lock.Acquire(filepath.Join(git.GitDir(), lockName), owner)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ctx.GlobalIsSet("remote-lock") {
+		return func() {
+			if err := file.Release(); err != nil {
+				logger.WithError(err).Error("Couldn't release the release lock")
+			}
+		}, nil
+	}
+
+	remote, err := lock.AcquireRemote(c, git, owner)
+	if err != nil {
+		if releaseErr := file.Release(); releaseErr != nil {
+			logger.WithError(releaseErr).Error("Couldn't release the release lock")
+		}
+		return nil, err
+	}
+	logger.WithFields(logrus.Fields{
+		"Ref": lock.RemoteRef,
+	}).Debug("Acquire the remote release lock")
+
+	return func() {
+		if err := remote.Release(context.Background()); err != nil {
+			logger.WithError(err).Error("Couldn't release the remote release lock")
+		}
+		if err := file.Release(); err != nil {
+			logger.WithError(err).Error("Couldn't release the release lock")
+		}
+	}, nil
+}
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"github.com/exaring/release-cli/pkg/release"
+	"github.com/sirupsen/logrus"
+)
+
+// logrusLogger adapts the logrus logger to the logger of the release package.
+type logrusLogger struct {
+	logrus.FieldLogger
+}
+
+// newLogger returns the adapter of the standard logrus logger.
+func newLogger() release.Logger {
+	return logrusLogger{logrus.StandardLogger()}
+}
+
+// WithFields returns the logger, which adds the fields to each entry.
+func (l logrusLogger) WithFields(fields release.Fields) release.Logger {
+	return logrusLogger{l.FieldLogger.WithFields(logrus.Fields(fields))}
+}
@@ -4,8 +4,14 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"sort"
-
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/exaring/release-cli/pkg/config"
+	"github.com/exaring/release-cli/pkg/fragment"
+	"github.com/exaring/release-cli/pkg/output"
+	"github.com/exaring/release-cli/pkg/release"
 	"github.com/exaring/release-cli/pkg/repository"
 	"github.com/exaring/release-cli/pkg/version"
 	"github.com/sirupsen/logrus"
@@ -23,8 +29,13 @@ func main() {
 	app.Version = Version
 
 	var (
-		flagMajor, flagMinor, flagPatch, flagPre, dryRun, force bool
-		flagBranch, flagLog                                     string
+		flagMajor, flagMinor, flagPatch, flagPre, dryRun, force, apiCheck, zipCheck, cutBranch bool
+		floating, floatingLatest, remoteLock, interactive                                      bool
+		retry                                                                                  int
+		flagBranch, flagLine, flagLog, flagOutput, flagOutputFile, tagPrefix, preLabel, remote string
+		backend                                                                                string
+		branchTemplate, genVersionFile, forgeKind, forgeURL, forgeToken                        string
+		trackerKind, trackerURL, trackerToken                                                  string
 	)
 
 	app.Flags = []cli.Flag{
@@ -64,12 +75,149 @@ func main() {
 			Usage:       "ignore untracked & uncommitted changes.",
 			EnvVar:      "FORCE",
 		},
+		cli.BoolFlag{
+			Name:        "i, interactive",
+			Destination: &interactive,
+			Usage:       "choose the increased version part and edit the tag message in a preview of the release. Requires a terminal.",
+		},
+		cli.BoolFlag{
+			Name:        "api-check",
+			Destination: &apiCheck,
+			Usage:       "compare the exported Go API with the previous version and refuse incompatible minor & patch releases.",
+			EnvVar:      "RELEASE_API_CHECK",
+		},
+		cli.BoolFlag{
+			Name:        "zip-check",
+			Destination: &zipCheck,
+			Usage:       "validate the Go module zip of the new version and add its h1: hash to the release result.",
+			EnvVar:      "RELEASE_ZIP_CHECK",
+		},
+		cli.BoolFlag{
+			Name:        "cut-branch",
+			Destination: &cutBranch,
+			Usage:       "create a maintenance branch of the new release line at the tagged commit and push it with the tag.",
+			EnvVar:      "RELEASE_CUT_BRANCH",
+		},
+		cli.BoolFlag{
+			Name:        "floating",
+			Destination: &floating,
+			Usage:       "move the alias tags of the major and minor line like v2 and v2.3 to final releases.",
+			EnvVar:      "RELEASE_FLOATING",
+		},
+		cli.BoolFlag{
+			Name:        "floating-latest",
+			Destination: &floatingLatest,
+			Usage:       "move the latest alias tag to the newest final release.",
+			EnvVar:      "RELEASE_FLOATING_LATEST",
+		},
+		cli.BoolFlag{
+			Name:        "remote-lock",
+			Destination: &remoteLock,
+			Usage:       "lock the release on the remote with the reference refs/release-lock/current besides the local lock.",
+			EnvVar:      "RELEASE_REMOTE_LOCK",
+		},
+		cli.IntFlag{
+			Name:        "retry",
+			Destination: &retry,
+			Usage:       "recompute the version with the remote tags up to the given times if the tag already exists on the remote.",
+			EnvVar:      "RELEASE_RETRY",
+		},
+		cli.StringFlag{
+			Name:        "gen-version-file",
+			Destination: &genVersionFile,
+			Usage:       "generate a Go file with the version, commit, date and previous version as variables and commit it before tagging.",
+			EnvVar:      "RELEASE_GEN_VERSION_FILE",
+		},
+		cli.StringFlag{
+			Name:        "forge",
+			Destination: &forgeKind,
+			Usage:       "create the release entry of the new tag on the forge: github, gitlab or gitea.",
+			EnvVar:      "RELEASE_FORGE",
+		},
+		cli.StringFlag{
+			Name:        "forge-url",
+			Destination: &forgeURL,
+			Usage:       "the base URL of the forge API. Defaults to the API of the public instance.",
+			EnvVar:      "RELEASE_FORGE_URL",
+		},
+		cli.StringFlag{
+			Name:        "forge-token",
+			Destination: &forgeToken,
+			Usage:       "the token of the forge API. Defaults to $GITHUB_TOKEN, $GITLAB_TOKEN or $GITEA_TOKEN.",
+			EnvVar:      "RELEASE_FORGE_TOKEN",
+		},
+		cli.StringFlag{
+			Name:        "tracker",
+			Destination: &trackerKind,
+			Usage:       "comment on the issues referenced by the released commits in the tracker: github, gitlab or jira.",
+			EnvVar:      "RELEASE_TRACKER",
+		},
+		cli.StringFlag{
+			Name:        "tracker-url",
+			Destination: &trackerURL,
+			Usage:       "the base URL of the tracker API. Defaults to the API of the public instance.",
+			EnvVar:      "RELEASE_TRACKER_URL",
+		},
+		cli.StringFlag{
+			Name:        "tracker-token",
+			Destination: &trackerToken,
+			Usage:       "the token of the tracker API. Defaults to $GITHUB_TOKEN, $GITLAB_TOKEN or $JIRA_TOKEN.",
+			EnvVar:      "RELEASE_TRACKER_TOKEN",
+		},
 		cli.StringFlag{
 			Name:        "b, branch",
 			Destination: &flagBranch,
 			Usage:       "only track tags related to the given branch when creating new version tags.",
 			EnvVar:      "ONLY_BRANCH",
 		},
+		cli.StringFlag{
+			Name:        "line",
+			Destination: &flagLine,
+			Usage:       "only track tags of the given release line, e.g. v2.4. Defaults to the line of the maintenance branch, which matches the branch template.",
+			EnvVar:      "RELEASE_LINE",
+		},
+		cli.StringFlag{
+			Name:        "branch-template",
+			Destination: &branchTemplate,
+			Usage:       "the name template of maintenance branches with {major} and {minor} placeholders. (default: \"release/{major}.{minor}\")",
+			EnvVar:      "RELEASE_BRANCH_TEMPLATE",
+		},
+		cli.StringFlag{
+			Name:        "tag-prefix",
+			Destination: &tagPrefix,
+			Usage:       "the prefix of the version tags. (default: \"v\")",
+			EnvVar:      "RELEASE_TAG_PREFIX",
+		},
+		cli.StringFlag{
+			Name:        "pre-label",
+			Destination: &preLabel,
+			Usage:       "the label of the release candidate versions. (default: \"RC\")",
+			EnvVar:      "RELEASE_PRE_LABEL",
+		},
+		cli.StringFlag{
+			Name:        "remote",
+			Destination: &remote,
+			Usage:       "the name of the remote the release is pushed to. (default: \"origin\")",
+			EnvVar:      "RELEASE_REMOTE",
+		},
+		cli.StringFlag{
+			Name:        "backend",
+			Destination: &backend,
+			Usage:       "the git client: go-git or exec, which runs the git command with the git setup of the user. (default: \"go-git\")",
+			EnvVar:      "RELEASE_BACKEND",
+		},
+		cli.StringFlag{
+			Name:        "o, output",
+			Destination: &flagOutput,
+			Usage:       "prints the release result to stdout in the given format: json, env, github or gitlab.",
+			EnvVar:      "RELEASE_OUTPUT",
+		},
+		cli.StringFlag{
+			Name:        "output-file",
+			Destination: &flagOutputFile,
+			Usage:       "appends the release result to the given file instead of stdout. Defaults to $GITHUB_OUTPUT for github.",
+			EnvVar:      "RELEASE_OUTPUT_FILE",
+		},
 		cli.StringFlag{
 			Name:        "l, log",
 			Destination: &flagLog,
@@ -78,36 +226,58 @@ func main() {
 		},
 	}
 
+	app.Before = setup
 	app.Action = run
+	app.Commands = []cli.Command{
+		currentCommand,
+		nextCommand,
+		listCommand,
+		compareCommand,
+		describeCommand,
+		retractCommand,
+		promoteCommand,
+		recoverCommand,
+		forgeCommand,
+		fragmentsCommand,
+		branchesCommand,
+		configCommand,
+	}
 	if err := app.Run(os.Args); err != nil {
-		logrus.WithError(err).Error("Couldn't release a new version")
-		os.Exit(1)
+		exit(err)
 	}
 }
 
-// Repository is a abstraction of the version control system client
-type Repository interface {
-	// LatestCommitHash returns the latest commit hash of the repository. In case of an error the result is empty.
-	LatestCommitHash() string
-	// ExistsTag validates the parameter version and returns the existence of the repository tag.
-	ExistsTag(version string) (bool, error)
-	// Tags lists all existing tags of the repository.
-	Tags() []string
-	// BranchTags lists all existing tags related to commits of the given branch.
-	BranchTags(branchName string) []string
-	// IsSafe validate the state of the repository and returns an error if the repository is unsafe like include uncommitted files
-	// or the local branch is behind the origin.
-	IsSafe(ctx context.Context) error
-	// CreateTag creates a local version control system tag.
-	CreateTag(tag string) error
-	// DeleteTag deletes a local version control system  tag.
-	DeleteTag(tag string) error
-	// Push pushes the local repo state to the origin.
-	Push(ctx context.Context) error
+// exit logs the error and terminates the application.
+func exit(err error) {
+	logrus.WithError(err).Error("Couldn't release a new version")
+	os.Exit(1)
+}
+
+// Repository is an abstraction of the version control system client
+type Repository = release.Repository
+
+// Client is the git client of the working tree, which is implemented by the go-git and the exec backend.
+type Client interface {
+	Repository
+	// Path returns the root directory of the working tree.
+	Path() string
+	// GitDir returns the git directory of the repository.
+	GitDir() string
+	// SetRemote sets the name of the remote which is fetched and pushed.
+	SetRemote(name string)
 }
 
-func run(ctx *cli.Context) error {
-	switch ctx.String("log") {
+func setup(ctx *cli.Context) error {
+	// keep stdout clean for the machine-readable output
+	logrus.SetOutput(os.Stderr)
+
+	// the config command reports the errors of the configuration files itself. Other errors are handled here,
+	// because the cli prints the whole help for errors of the before function.
+	if err := loadSettings(ctx); err != nil && ctx.Args().First() != configCommand.Name {
+		exit(err)
+	}
+
+	switch ctx.GlobalString("log") {
 	case "debug":
 		logrus.SetLevel(logrus.DebugLevel)
 	case "error":
@@ -115,138 +285,198 @@ func run(ctx *cli.Context) error {
 	default:
 		logrus.SetLevel(logrus.InfoLevel)
 	}
+	return nil
+}
+
+// signalContext returns the context of a command, which is canceled by an interrupt or termination signal, so that
+// the running git commands, hooks and requests stop and the release is rolled back. Stop restores the default handling
+// of the signals.
+func signalContext() (c context.Context, stop func()) {
+	c, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case sig := <-signals:
+			logrus.WithFields(logrus.Fields{
+				"Signal": sig,
+			}).Warn("Stop the release")
+			cancel()
+		case <-c.Done():
+		}
+	}()
+	return c, func() {
+		signal.Stop(signals)
+		cancel()
+	}
+}
+
+func run(ctx *cli.Context) (err error) {
 	logger := logrus.StandardLogger()
-	dryModus := ctx.IsSet("dry")
+	c, stop := signalContext()
+	defer stop()
 
-	currentPath, err := os.Getwd()
-	if err != nil {
-		return fmt.Errorf("failed to get current directory: %w", err)
+	if ctx.IsSet("output") {
+		if err := output.Check(ctx.String("output")); err != nil {
+			return err
+		}
 	}
 
 	logger.Debug("Read the directory")
 
-	var repo Repository
-	repo, err = repository.New(currentPath)
+	git, err := openRepository(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to open the git repository metadata directory: %w", err)
+		return err
 	}
 
+	var repo Repository = git
+
 	if ctx.IsSet("dry") {
-		repo = repository.NewNoOp()
+		repo = repository.NewNoOp(git)
+	}
+
+	unlock, err := acquireLocks(c, ctx, git)
+	if err != nil {
+		return err
 	}
+	defer unlock()
 
-	logger.Debug("Analyse the git repository")
+	hooks := newHooks(ctx, git)
+	defer func() { hooks.Failed(err) }()
+	if err := hooks.Run(c, config.HookPreCheck); err != nil {
+		return err
+	}
+
+	line, err := releaseLine(ctx, repo)
+	if err != nil {
+		return err
+	}
 
-	var currentTag version.Version
-	if ctx.IsSet("branch") {
-		currentTag, err = LatestBranchTag(repo, ctx.String("branch"))
+	var message string
+	if ctx.IsSet("interactive") {
+		releaser, err := newReleaser(ctx, git, repo, line, "", hooks)
 		if err != nil {
-			return fmt.Errorf("failed to fetch the tag on the given branch: %w", err)
+			return err
 		}
-	} else {
-		currentTag, err = LatestTag(repo)
+		policy, err := releaser.Check(c)
 		if err != nil {
-			return fmt.Errorf("failed to fetch the tag in the repository: %w", err)
+			return err
+		}
+		fragments, err := fragment.Read(git.Path())
+		if err != nil {
+			return err
+		}
+		if message, err = chooseRelease(ctx, repo, releaser.Format(policy), policy, line, fragments); err != nil {
+			return err
 		}
 	}
-	logger.WithFields(logrus.Fields{
-		"Tag": currentTag,
-	}).Debug("Detect latest tag of the repository")
-
-	currentTag.Increase(
-		ctx.IsSet("major"),
-		ctx.IsSet("minor"),
-		ctx.IsSet("patch"),
-		ctx.IsSet("pre"))
-	logger.WithFields(logrus.Fields{
-		"Tag": currentTag,
-	}).Info("Create new releasing version")
 
-	if err := repo.IsSafe(context.Background()); !ctx.IsSet("force") && err != nil {
-		return fmt.Errorf("repository is in unsafe state and force is not set: %w", err)
+	releaser, err := newReleaser(ctx, git, repo, line, message, hooks)
+	if err != nil {
+		return err
 	}
-
-	if err := repo.CreateTag(currentTag.String()); err != nil {
-		if deleteErr := repo.DeleteTag(currentTag.String()); deleteErr != nil {
-			logger.WithError(deleteErr).Errorf("Couldn't remove the creates tag: %v", currentTag)
-		}
-		return fmt.Errorf("failed to create tag: %w", err)
+	plan, err := releaser.Plan(c)
+	if err != nil {
+		return err
 	}
-	logger.WithFields(logrus.Fields{
-		"Version": currentTag,
-	}).Debug("Tagging the current repository")
+	hooks.Update(&release.Result{Plan: plan, Commit: plan.Commit})
 
-	if err := repo.Push(context.Background()); err != nil {
-		if deleteErr := repo.DeleteTag(currentTag.String()); deleteErr != nil {
-			logger.WithError(deleteErr).Errorf("Couldn't remove the creates tag: %v", currentTag)
-		}
-		return fmt.Errorf("failed to push tag: %w", err)
+	result, err := releaser.Execute(c, plan)
+	if err != nil {
+		return err
 	}
-	logger.WithFields(logrus.Fields{
-		"Version": currentTag,
-	}).Debug("Pushing new tag to the origin repository")
+	hooks.Update(result)
+	hooks.Pushed(c)
+	publishForge(c, ctx, git, plan.Format, plan.Next, result.Notes)
+	updateIssues(c, ctx, git, plan.Format, plan.Next, result.Commit)
+	notifyWebhooks(c, ctx, hooks.release)
 
-	if ctx.IsSet("branch") {
-		currentTag, err = LatestBranchTag(repo, ctx.String("branch"))
-		if err != nil {
-			return fmt.Errorf("failed to fetch the tag on the given branch: %w", err)
-		}
-	} else {
-		currentTag, err = LatestTag(repo)
-		if err != nil {
-			return fmt.Errorf("failed to fetch the tag in the repository: %w", err)
-		}
+	latest, err := releaser.Latest(c)
+	if err != nil {
+		return err
 	}
 
-	if dryModus {
+	if ctx.IsSet("dry") {
 		logger.Info("Don't publish the new releases, because of the dry-run mode")
-		return nil
+		return WriteOutput(ctx, hooks.release)
 	}
 
 	logger.WithFields(logrus.Fields{
-		"Version": currentTag,
+		"Version": latest,
 	}).Info("Release new version")
 
-	return nil
+	return WriteOutput(ctx, hooks.release)
 }
 
-// LatestTag returns the latest tag of the repository.
-func LatestTag(vc Repository) (version.Version, error) {
-	var lightweightTags version.Versions
-	for _, tag := range vc.Tags() {
-		o, err := version.New(tag)
-		if err != nil {
-			return version.Version{}, err
-		}
-		lightweightTags = append(lightweightTags, o)
+// newReleaser returns the releaser of the global flags and the settings, which runs the hooks with the details of the
+// release.
+func newReleaser(ctx *cli.Context, git Client, repo Repository, line *version.Line, message string, hooks *releaseHooks) (*release.Releaser, error) {
+	template, err := branchTemplate(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	sort.Sort(lightweightTags)
-
-	if len(lightweightTags) > 0 {
-		return lightweightTags[len(lightweightTags)-1], nil
+	var journal string
+	if !ctx.GlobalIsSet("dry") {
+		journal = filepath.Join(git.GitDir(), journalName)
 	}
 
-	return version.Version{}, fmt.Errorf("the version list is empty")
+	return release.New(repo, release.Options{
+		Dir:            git.Path(),
+		Format:         tagFormat(ctx),
+		Branch:         ctx.GlobalString("branch"),
+		Line:           line,
+		Branches:       settings.Branches,
+		Major:          ctx.GlobalIsSet("major"),
+		Minor:          ctx.GlobalIsSet("minor"),
+		Patch:          ctx.GlobalIsSet("patch"),
+		Pre:            ctx.GlobalIsSet("pre"),
+		Force:          ctx.GlobalIsSet("force"),
+		APICheck:       ctx.GlobalIsSet("api-check"),
+		ZipCheck:       ctx.GlobalIsSet("zip-check"),
+		Floating:       ctx.GlobalIsSet("floating"),
+		FloatingLatest: ctx.GlobalIsSet("floating-latest"),
+		CutBranch:      ctx.GlobalIsSet("cut-branch"),
+		BranchTemplate: template,
+		Retry:          ctx.GlobalInt("retry"),
+		VersionFiles:   settings.VersionFiles,
+		GenVersionFile: ctx.GlobalString("gen-version-file"),
+		Changelog:      settings.Changelog.File,
+		Message:        message,
+		Journal:        journal,
+		Dry:            ctx.GlobalIsSet("dry"),
+		Hook: func(c context.Context, name string, result *release.Result) error {
+			hooks.Update(result)
+			return hooks.Run(c, name)
+		},
+	}, newLogger()), nil
 }
 
-// LatestBranchTag returns the latest tag of the given branch.
-func LatestBranchTag(vc Repository, branchName string) (version.Version, error) {
-	var branchTags version.Versions
-	for _, tag := range vc.BranchTags(branchName) {
-		o, err := version.New(tag)
-		if err != nil {
-			return version.Version{}, err
-		}
-		branchTags = append(branchTags, o)
+// WriteOutput writes the release result in the format of the output flag to stdout or the output file.
+func WriteOutput(ctx *cli.Context, result output.Release) error {
+	format := ctx.GlobalString("output")
+	if format == "" {
+		return nil
 	}
 
-	sort.Sort(branchTags)
-
-	if len(branchTags) > 0 {
-		return branchTags[len(branchTags)-1], nil
+	file := ctx.GlobalString("output-file")
+	if file == "" && format == output.GitHub {
+		if file = os.Getenv("GITHUB_OUTPUT"); file == "" {
+			return fmt.Errorf("the github output requires the GITHUB_OUTPUT environment variable or an output file")
+		}
+	}
+	if file == "" {
+		return result.Write(ctx.App.Writer, format)
 	}
 
-	return version.Version{}, fmt.Errorf("the master branch version list is empty")
+	f, err := os.OpenFile(file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open the output file: %w", err)
+	}
+	defer f.Close()
 
+	if err := result.Write(f, format); err != nil {
+		return fmt.Errorf("failed to write the output file: %w", err)
+	}
+	return f.Close()
 }
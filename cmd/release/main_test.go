@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/exaring/release-cli/pkg/releasetest"
+	"github.com/exaring/release-cli/pkg/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "update the golden files of the end-to-end tests.")
+
+// binary is the path of the release command, which is built for the end-to-end tests.
+var binary string
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+	dir, err := ioutil.TempDir("", "release-cli")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	binary = filepath.Join(dir, "release")
+	if out, err := exec.Command("go", "build", "-o", binary, ".").CombinedOutput(); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to build the release command: %v\n%s", err, out)
+		os.RemoveAll(dir)
+		os.Exit(1)
+	}
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// history is the history of all scenarios. The master branch has the versions v1.0.0 and v1.1.0, which is an
+// annotated tag, and an unreleased commit. The maintenance branch release/1.0 has the patch v1.0.1.
+func history() []releasetest.Step {
+	return []releasetest.Step{
+		releasetest.File("README.md", "# app\n"),
+		releasetest.Commit("Initial commit"),
+		releasetest.Tag("v1.0.0"),
+		releasetest.File("api.go", "package app\n"),
+		releasetest.Commit("Add the API"),
+		releasetest.AnnotatedTag("v1.1.0", "Release v1.1.0"),
+		releasetest.Checkout("v1.0.0"),
+		releasetest.NewBranch("release/1.0"),
+		releasetest.Commit("Fix the README"),
+		releasetest.Tag("v1.0.1"),
+		releasetest.Checkout("master"),
+		releasetest.Commit("Add a feature"),
+		releasetest.Push(),
+	}
+}
+
+// timestamps matches the time of the log lines.
+var timestamps = regexp.MustCompile(`time="[^"]*" `)
+
+// rejections matches the reason of a rejected push in the log lines, which depends on the backend and the version of
+// git.
+var rejections = regexp.MustCompile(`(failed to push [^:"]*): [^"]*"`)
+
+// transcript runs the release command with the arguments in the repository. It returns the command, its output, the
+// log lines, the status of the working tree and the tags of the origin afterwards with the placeholder $DIR of the
+// temporary directory, $REASON of the reason of a rejected push and $RELEASE_COMMIT of a new commit of the command,
+// whose hash depends on the clock of go-git.
+func transcript(t *testing.T, repo *releasetest.Repository, args ...string) string {
+	head := repo.Git("rev-parse", "HEAD")
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(binary, args...)
+	cmd.Dir = repo.Path
+	cmd.Env = append(repo.Env(), "LOG_LEVEL=info")
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	err := cmd.Run()
+	if _, ok := err.(*exec.ExitError); err != nil && !ok {
+		t.Fatalf("failed to run the release command: %v", err)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "$ release %v\n", strings.Join(args, " "))
+	b.WriteString(stdout.String())
+	fmt.Fprintf(&b, "# exit code %v\n", cmd.ProcessState.ExitCode())
+	log := timestamps.ReplaceAllString(stderr.String(), "")
+	fmt.Fprintf(&b, "# log\n%s", rejections.ReplaceAllString(log, `$1: $$REASON"`))
+	fmt.Fprintf(&b, "# status\n%v\n", repo.Git("status", "--porcelain"))
+	fmt.Fprintf(&b, "# origin tags\n%v\n", repo.Refs(repo.Origin, "refs/tags"))
+	got := strings.Replace(b.String(), repo.Dir, "$DIR", -1)
+	if commit := repo.Git("rev-parse", "HEAD"); commit != head {
+		got = strings.Replace(got, commit, "$RELEASE_COMMIT", -1)
+	}
+	return got
+}
+
+// TestEndToEnd runs the release command against the scenarios with each backend. The output is compared to the
+// golden files in testdata, which are the same for all backends. The flag -update rewrites the golden files with the
+// output of the first backend.
+func TestEndToEnd(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []releasetest.Step
+		args  []string
+	}{
+		{name: "current", args: []string{"current"}},
+		{name: "list", args: []string{"list", "--output", "json"}},
+		{name: "list_branch", args: []string{"--branch", "release/1.0", "list"}},
+		{name: "list_foreign_tags", steps: []releasetest.Step{
+			releasetest.Tag("deploy-prod"),
+			releasetest.Tag("latest"),
+			releasetest.Tag("v1.2.0-beta.1"),
+		}, args: []string{"list"}},
+		{name: "current_release_output", steps: []releasetest.Step{
+			releasetest.File(".release.yaml", "output: github\n"),
+			releasetest.Commit("Write the release result for GitHub Actions"),
+		}, args: []string{"current"}},
+		{name: "next", args: []string{"next", "--minor"}},
+		{name: "next_feature_branch", steps: []releasetest.Step{releasetest.NewBranch("hotfix/1.0")},
+			args: []string{"next"}},
+		{name: "compare", args: []string{"--tag-prefix", "api/v", "--pre-label", "beta",
+			"compare", "api/v1.2.0-beta.2", "api/v1.2.0"}},
+		{name: "compare_invalid", args: []string{"compare", "foo", "bar"}},
+		{name: "describe", args: []string{"describe"}},
+		{name: "release_dry", args: []string{"--dry", "--output", "json"}},
+		{name: "release_pre_label", args: []string{"--pre-label", "beta", "--pre", "--dry", "--output", "json"}},
+		{name: "release", args: []string{"--output", "json"}},
+		{name: "release_zip_check", steps: []releasetest.Step{
+			releasetest.File("go.mod", "module example.com/app\n\ngo 1.16\n"),
+			releasetest.Commit("Add the go.mod file"),
+			releasetest.Push(),
+		}, args: []string{"--zip-check", "--dry", "--output", "json"}},
+		{name: "release_version_file_floating", steps: []releasetest.Step{
+			releasetest.File("VERSION", "1.1.0\n"),
+			releasetest.File(".release.yaml", "version-files:\n  - path: VERSION\n"),
+			releasetest.Commit("Add the version file"),
+			releasetest.Push(),
+		}, args: []string{"--floating", "--floating-latest", "--output", "json"}},
+		{name: "release_zip_check_version_file", steps: []releasetest.Step{
+			releasetest.File("go.mod", "module example.com/app\n\ngo 1.16\n"),
+			releasetest.File("VERSION", "1.1.0\n"),
+			releasetest.File(".release.yaml", "version-files:\n  - path: VERSION\n"),
+			releasetest.Commit("Add the go.mod and the version file"),
+			releasetest.Push(),
+		}, args: []string{"--zip-check", "--output", "json"}},
+		{name: "release_ahead", steps: []releasetest.Step{releasetest.Commit("Unpushed change")},
+			args: []string{"--minor"}},
+		{name: "release_maintenance", steps: []releasetest.Step{releasetest.Checkout("release/1.0")},
+			args: []string{"--output", "json"}},
+		{name: "release_forced", steps: []releasetest.Step{releasetest.File("notes.txt", "untracked")},
+			args: []string{"--force", "--dry"}},
+		{name: "promote_undotted", steps: []releasetest.Step{
+			releasetest.File(".release.yaml", "branches:\n  - name: master\n    parts: [patch]\n"),
+			releasetest.Commit("Allow only patch releases"),
+			releasetest.Tag("v1.2.0-RC1"),
+			releasetest.Push(),
+		}, args: []string{"promote"}},
+		{name: "retract_rejected", steps: []releasetest.Step{
+			releasetest.File("go.mod", "module example.com/app\n\ngo 1.16\n"),
+			releasetest.Commit("Add the go.mod file"),
+			releasetest.Push(),
+			releasetest.Remote(releasetest.Commit("Concurrent change")),
+		}, args: []string{"--force", "retract", "--reason", "broken API", "v1.1.0"}},
+		{name: "unsafe_untracked", steps: []releasetest.Step{releasetest.File("notes.txt", "untracked")}},
+		{name: "unsafe_modified", steps: []releasetest.Step{releasetest.File("README.md", "modified")}},
+		{name: "unsafe_staged", steps: []releasetest.Step{
+			releasetest.File("README.md", "staged"),
+			releasetest.Stage("README.md"),
+		}},
+		{name: "unsafe_behind", steps: []releasetest.Step{
+			releasetest.Remote(releasetest.Commit("Concurrent change")),
+		}},
+		{name: "unsafe_diverged", steps: []releasetest.Step{
+			releasetest.Commit("Local change"),
+			releasetest.Remote(releasetest.Commit("Concurrent change")),
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			golden := filepath.Join("testdata", tt.name+".golden")
+			for i, backend := range repository.Backends {
+				t.Run(backend, func(t *testing.T) {
+					repo := releasetest.New(t, append(history(), tt.steps...)...)
+					defer repo.Remove()
+
+					got := transcript(t, repo, append([]string{"--backend", backend}, tt.args...)...)
+					got = strings.Replace(got, "--backend "+backend, "--backend $BACKEND", 1)
+					if *update && i == 0 {
+						assert.NoError(t, ioutil.WriteFile(golden, []byte(got), 0644))
+					}
+					want, err := ioutil.ReadFile(golden)
+					assert.NoError(t, err)
+					assert.Equal(t, string(want), got)
+				})
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/exaring/release-cli/pkg/notify"
+	"github.com/exaring/release-cli/pkg/output"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+)
+
+// notifyWebhooks posts the release to the configured webhooks. The release is already pushed, so the errors are only
+// logged. The dry-run mode doesn't notify any webhook.
+func notifyWebhooks(c context.Context, ctx *cli.Context, release output.Release) {
+	logger := logrus.StandardLogger()
+
+	for _, w := range settings.Notify {
+		opts := notify.Options{
+			URL:      os.ExpandEnv(w.URL),
+			Preset:   w.Preset,
+			Template: w.Template,
+			Headers:  make(map[string]string, len(w.Headers)),
+			Retries:  w.Retries,
+		}
+		for name, value := range w.Headers {
+			opts.Headers[name] = os.ExpandEnv(value)
+		}
+		// the timeout is validated with the configuration
+		opts.Timeout, _ = time.ParseDuration(w.Timeout)
+
+		webhook, err := notify.New(opts)
+		if err != nil {
+			logger.WithError(err).Error("Couldn't notify the webhook")
+			continue
+		}
+		fields := logrus.Fields{
+			"Host":   webhook.Host(),
+			"Preset": w.Preset,
+		}
+
+		if ctx.GlobalIsSet("dry") {
+			logger.WithFields(fields).Info("Don't notify the webhook, because of the dry-run mode")
+			continue
+		}
+		if err := webhook.Send(c, release); err != nil {
+			logger.WithError(err).Error("Couldn't notify the webhook")
+			continue
+		}
+		logger.WithFields(fields).Info("Notify the webhook")
+	}
+}
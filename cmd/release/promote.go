@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/exaring/release-cli/pkg/config"
+	"github.com/exaring/release-cli/pkg/release"
+	"github.com/exaring/release-cli/pkg/repository"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+)
+
+var promoteCommand = cli.Command{
+	Name:      "promote",
+	Usage:     "tag the commit of a release candidate with its final version. Defaults to the latest release candidate.",
+	ArgsUsage: "[<release candidate>]",
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name:  "check-commits",
+			Usage: "refuse the promotion if the current branch has commits on top of the release candidate.",
+		},
+	},
+	Action: promote,
+}
+
+func promote(ctx *cli.Context) (err error) {
+	logger := logrus.StandardLogger()
+	c, stop := signalContext()
+	defer stop()
+
+	if ctx.NArg() > 1 {
+		return fmt.Errorf("expected at most one release candidate, got %d arguments", ctx.NArg())
+	}
+
+	git, err := openRepository(ctx)
+	if err != nil {
+		return err
+	}
+
+	var repo Repository = git
+	if ctx.GlobalIsSet("dry") {
+		repo = repository.NewNoOp(git)
+	}
+
+	unlock, err := acquireLocks(c, ctx, git)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	hooks := newHooks(ctx, git)
+	defer func() { hooks.Failed(err) }()
+	if err := hooks.Run(c, config.HookPreCheck); err != nil {
+		return err
+	}
+
+	line, err := releaseLine(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	releaser, err := newReleaser(ctx, git, repo, line, "", hooks)
+	if err != nil {
+		return err
+	}
+	plan, err := releaser.PlanPromotion(c, ctx.Args().First(), ctx.IsSet("check-commits"))
+	if err != nil {
+		return err
+	}
+	hooks.Update(&release.Result{Plan: plan, Commit: plan.Commit})
+
+	result, err := releaser.Execute(c, plan)
+	if err != nil {
+		return err
+	}
+	hooks.Update(result)
+	hooks.Pushed(c)
+	publishForge(c, ctx, git, plan.Format, plan.Next, "")
+	updateIssues(c, ctx, git, plan.Format, plan.Next, result.Commit)
+	notifyWebhooks(c, ctx, hooks.release)
+
+	if ctx.GlobalIsSet("dry") {
+		logger.Info("Don't publish the new releases, because of the dry-run mode")
+		return WriteOutput(ctx, hooks.release)
+	}
+
+	logger.WithFields(logrus.Fields{
+		"Version": plan.Tag,
+	}).Info("Release new version")
+
+	return WriteOutput(ctx, hooks.release)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/exaring/release-cli/pkg/transaction"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+)
+
+// journalName is the file name of the journal of unfinished releases in the git directory.
+const journalName = "release-journal.json"
+
+var recoverCommand = cli.Command{
+	Name:  "recover",
+	Usage: "finish or roll back an interrupted release recorded in the journal of the git directory.",
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name:  "rollback",
+			Usage: "undo the completed steps of the release instead of finishing it.",
+		},
+	},
+	Action: recoverRelease,
+}
+
+func recoverRelease(ctx *cli.Context) error {
+	logger := logrus.StandardLogger()
+	c, stop := signalContext()
+	defer stop()
+
+	git, err := openRepository(ctx)
+	if err != nil {
+		return err
+	}
+
+	file := filepath.Join(git.GitDir(), journalName)
+	tx, err := transaction.Open(git, newLogger(), file)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("there is no interrupted release to recover")
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, step := range tx.Steps {
+		logger.WithFields(logrus.Fields{
+			"Kind": step.Kind,
+			"Done": step.Done,
+		}).Infof("Release step of %v", tx.Name)
+	}
+
+	if ctx.GlobalIsSet("dry") {
+		logger.Info("Don't recover the release, because of the dry-run mode")
+		return nil
+	}
+
+	if ctx.IsSet("rollback") {
+		if err := tx.Rollback(c); err != nil {
+			return err
+		}
+		logger.WithFields(logrus.Fields{
+			"Name": tx.Name,
+		}).Info("Roll back the interrupted release")
+		return nil
+	}
+
+	if err := tx.Run(c); err != nil {
+		return err
+	}
+	logger.WithFields(logrus.Fields{
+		"Name": tx.Name,
+	}).Info("Finish the interrupted release")
+	return nil
+}
+
+// beginRelease starts the transaction of a release, which is recorded in the journal of the git directory. The
+// dry-run mode doesn't record the transaction.
+func beginRelease(ctx *cli.Context, git Client, vc Repository, name string) (*transaction.Transaction, error) {
+	var file string
+	if !ctx.GlobalIsSet("dry") {
+		file = filepath.Join(git.GitDir(), journalName)
+	}
+
+	return transaction.Begin(vc, newLogger(), file, name)
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/exaring/release-cli/pkg/release"
+	"github.com/exaring/release-cli/pkg/repository"
+	"github.com/exaring/release-cli/pkg/version"
+	"github.com/urfave/cli"
+)
+
+var outputFlag = cli.StringFlag{
+	Name:  "o, output",
+	Value: "text",
+	Usage: "the output format: text or json.",
+}
+
+var currentCommand = cli.Command{
+	Name:   "current",
+	Usage:  "print the latest version.",
+	Flags:  []cli.Flag{outputFlag},
+	Action: current,
+}
+
+var nextCommand = cli.Command{
+	Name:  "next",
+	Usage: "print the version which would be released.",
+	Flags: []cli.Flag{
+		cli.BoolFlag{Name: "major", Usage: "increase major version part."},
+		cli.BoolFlag{Name: "minor", Usage: "increase minor version part."},
+		cli.BoolFlag{Name: "patch", Usage: "increase patch version part. This is the default increased part."},
+		cli.BoolFlag{Name: "pre", Usage: "increase release candidate version part."},
+		outputFlag,
+	},
+	Action: next,
+}
+
+var compareCommand = cli.Command{
+	Name:      "compare",
+	Usage:     "print the ordering of two versions and the first differing version part.",
+	ArgsUsage: "<version> <version>",
+	Flags:     []cli.Flag{outputFlag},
+	Action:    compare,
+}
+
+// versionInfo is the JSON representation of a version.
+type versionInfo struct {
+	Version   string `json:"version"`
+	Tag       string `json:"tag"`
+	Major     uint   `json:"major"`
+	Minor     uint   `json:"minor"`
+	Patch     uint   `json:"patch"`
+	Pre       uint   `json:"pre"`
+	Retracted bool   `json:"retracted,omitempty"`
+	Rationale string `json:"rationale,omitempty"`
+}
+
+func newVersionInfo(format version.Format, v version.Version) versionInfo {
+	return versionInfo{
+		Version: format.Version(v),
+		Tag:     format.Tag(v),
+		Major:   v[version.Major],
+		Minor:   v[version.Minor],
+		Patch:   v[version.Patch],
+		Pre:     v[version.Pre],
+	}
+}
+
+// openRepository opens the git repository of the current directory with the configured backend and remote.
+func openRepository(ctx *cli.Context) (Client, error) {
+	currentPath, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	var repo Client
+	switch backend := ctx.GlobalString("backend"); backend {
+	case "", repository.GoGit:
+		repo, err = repository.New(currentPath)
+	case repository.Command:
+		repo, err = repository.NewExec(currentPath)
+	default:
+		return nil, fmt.Errorf("unknown git backend %q, expected one of %v", backend, repository.Backends)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the git repository metadata directory: %w", err)
+	}
+	repo.SetRemote(ctx.GlobalString("remote"))
+
+	return repo, nil
+}
+
+// printOutput prints the value as JSON or the text in the output format of the command. Only the output flag of the
+// command applies, because the global output flag and RELEASE_OUTPUT choose the format of the release result.
+func printOutput(ctx *cli.Context, text string, value interface{}) error {
+	switch format := ctx.String("output"); format {
+	case "json":
+		return json.NewEncoder(ctx.App.Writer).Encode(value)
+	case "text", "":
+		if text == "" {
+			return nil
+		}
+		_, err := fmt.Fprintln(ctx.App.Writer, text)
+		return err
+	default:
+		return fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+func current(ctx *cli.Context) error {
+	format := tagFormat(ctx)
+	repo, err := openRepository(ctx)
+	if err != nil {
+		return err
+	}
+
+	v, err := release.LatestVersion(repo, format, ctx.GlobalString("branch"), nil)
+	if err != nil {
+		return err
+	}
+
+	return printOutput(ctx, format.Tag(v), newVersionInfo(format, v))
+}
+
+func next(ctx *cli.Context) error {
+	format := tagFormat(ctx)
+	repo, err := openRepository(ctx)
+	if err != nil {
+		return err
+	}
+
+	line, err := releaseLine(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	v, err := release.LatestVersion(repo, format, ctx.GlobalString("branch"), line)
+	if err != nil {
+		return err
+	}
+
+	isSet := func(name string) bool {
+		return ctx.IsSet(name) || ctx.GlobalIsSet(name)
+	}
+
+	nextVersion := make(version.Version, len(v))
+	copy(nextVersion, v)
+	nextVersion.Increase(isSet("major"), isSet("minor"), isSet("patch"), isSet("pre"))
+
+	return printOutput(ctx, format.Tag(nextVersion), struct {
+		Current versionInfo `json:"current"`
+		Next    versionInfo `json:"next"`
+		Part    string      `json:"part"`
+	}{
+		Current: newVersionInfo(format, v),
+		Next:    newVersionInfo(format, nextVersion),
+		Part:    version.PartName(version.DifferingPart(v, nextVersion)),
+	})
+}
+
+func compare(ctx *cli.Context) error {
+	format := tagFormat(ctx)
+	if ctx.NArg() != 2 {
+		return fmt.Errorf("expected two versions, got %d arguments", ctx.NArg())
+	}
+
+	a, err := format.Parse(ctx.Args().Get(0))
+	if err != nil {
+		return err
+	}
+	b, err := format.Parse(ctx.Args().Get(1))
+	if err != nil {
+		return err
+	}
+
+	order, part := version.Compare(a, b), version.DifferingPart(a, b)
+
+	text := fmt.Sprintf("%v %v %v", format.Tag(a), map[int]string{-1: "<", 0: "=", 1: ">"}[order], format.Tag(b))
+	if part >= 0 {
+		text += fmt.Sprintf(" (%v)", version.PartName(part))
+	}
+
+	return printOutput(ctx, text, struct {
+		A     versionInfo `json:"a"`
+		B     versionInfo `json:"b"`
+		Order int         `json:"order"`
+		Part  string      `json:"part,omitempty"`
+	}{
+		A:     newVersionInfo(format, a),
+		B:     newVersionInfo(format, b),
+		Order: order,
+		Part:  version.PartName(part),
+	})
+}
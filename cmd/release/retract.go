@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/exaring/release-cli/pkg/config"
+	"github.com/exaring/release-cli/pkg/gomod"
+	"github.com/exaring/release-cli/pkg/release"
+	"github.com/exaring/release-cli/pkg/repository"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+)
+
+var retractCommand = cli.Command{
+	Name:      "retract",
+	Usage:     "retract a broken version or version range in the go.mod file and release the next patch version.",
+	ArgsUsage: "<version> [<highest version>]",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "r, reason",
+			Usage: "the rationale of the retraction, which is shown by the go command.",
+		},
+	},
+	Action: retract,
+}
+
+func retract(ctx *cli.Context) (err error) {
+	logger := logrus.StandardLogger()
+	c, stop := signalContext()
+	defer stop()
+
+	if ctx.NArg() < 1 || ctx.NArg() > 2 {
+		return fmt.Errorf("expected a version or a version range, got %d arguments", ctx.NArg())
+	}
+	retraction := gomod.Retraction{
+		Low:       ctx.Args().Get(0),
+		High:      ctx.Args().Get(1),
+		Rationale: ctx.String("reason"),
+	}
+	if retraction.High == "" {
+		retraction.High = retraction.Low
+	}
+
+	git, err := openRepository(ctx)
+	if err != nil {
+		return err
+	}
+
+	var repo Repository = git
+	if ctx.GlobalIsSet("dry") {
+		repo = repository.NewNoOp(git)
+	}
+
+	unlock, err := acquireLocks(c, ctx, git)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	hooks := newHooks(ctx, git)
+	defer func() { hooks.Failed(err) }()
+	if err := hooks.Run(c, config.HookPreCheck); err != nil {
+		return err
+	}
+
+	line, err := releaseLine(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	releaser, err := newReleaser(ctx, git, repo, line, "", hooks)
+	if err != nil {
+		return err
+	}
+	plan, err := releaser.PlanRetraction(c, retraction)
+	if err != nil {
+		return err
+	}
+	hooks.Update(&release.Result{Plan: plan, Commit: plan.Commit})
+
+	result, err := releaser.Execute(c, plan)
+	if err != nil {
+		return err
+	}
+	hooks.Update(result)
+	hooks.Pushed(c)
+
+	if ctx.GlobalIsSet("dry") {
+		logger.Info("Don't publish the new releases, because of the dry-run mode")
+		return nil
+	}
+
+	logger.WithFields(logrus.Fields{
+		"Version": plan.Tag,
+	}).Info("Release new version")
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/exaring/release-cli/pkg/config"
+	"github.com/exaring/release-cli/pkg/forge"
+	"github.com/exaring/release-cli/pkg/tracker"
+	"github.com/exaring/release-cli/pkg/version"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+)
+
+// updateIssues updates the issues referenced by the released commits if a tracker is configured. The tag is already
+// pushed, so the errors are only logged. The dry-run mode only lists the issues.
+func updateIssues(c context.Context, ctx *cli.Context, git Client, format version.Format, v version.Version, hash string) {
+	if !ctx.GlobalIsSet("tracker") {
+		return
+	}
+	if err := UpdateIssues(c, ctx, git, format, v, hash); err != nil {
+		logrus.WithError(err).Error("Couldn't update the issues of the release")
+	}
+}
+
+// UpdateIssues comments on the issues, which are referenced by the commits since the previous version up to the
+// released commit, and labels, closes or transitions them as configured.
+func UpdateIssues(c context.Context, ctx *cli.Context, git Client, format version.Format, v version.Version, hash string) error {
+	logger := logrus.StandardLogger()
+
+	opts := tracker.Options{
+		BaseURL:    ctx.GlobalString("tracker-url"),
+		Token:      trackerAPIToken(ctx),
+		User:       settings.Tracker.User,
+		Repository: settings.Tracker.Repository,
+		Project:    settings.Tracker.Project,
+		Transition: settings.Tracker.Transition,
+	}
+	kind := ctx.GlobalString("tracker")
+	if opts.Repository == "" && kind != tracker.Jira {
+		remoteURL, err := git.RemoteURL()
+		if err != nil {
+			return fmt.Errorf("failed to detect the remote URL: %w", err)
+		}
+		if opts.Repository, err = forge.RepositoryPath(remoteURL); err != nil {
+			return err
+		}
+	}
+	issues, err := tracker.New(kind, opts)
+	if err != nil {
+		return err
+	}
+
+	commits, err := git.Commits(previousRelease(git, format, v), hash)
+	if err != nil {
+		return fmt.Errorf("failed to list the commits of the release: %w", err)
+	}
+	// the commits are listed newest first, but the issues are updated in the order of their first reference
+	messages := make([]string, 0, len(commits))
+	for i := len(commits) - 1; i >= 0; i-- {
+		messages = append(messages, commits[i].Message)
+	}
+
+	tag := format.Tag(v)
+	for _, ref := range issues.References(messages) {
+		update := tracker.Update{
+			Version:    tag,
+			Comment:    "Released in " + tag,
+			Label:      settings.Tracker.Label,
+			FixVersion: config.Enabled(settings.Tracker.FixVersion),
+			Close:      config.Enabled(settings.Tracker.Close) && ref.Close,
+		}
+		fields := logrus.Fields{
+			"Issue": ref.ID,
+			"Label": update.Label,
+			"Close": update.Close,
+		}
+
+		if ctx.GlobalIsSet("dry") {
+			logger.WithFields(fields).Info("Don't update the issue, because of the dry-run mode")
+			continue
+		}
+		if err := issues.Update(c, ref.ID, update); err != nil {
+			return err
+		}
+		logger.WithFields(fields).Info("Update the issue")
+	}
+	return nil
+}
+
+// trackerAPIToken returns the token of the tracker-token flag or of the usual environment variable of the tracker like
+// JIRA_TOKEN.
+func trackerAPIToken(ctx *cli.Context) string {
+	if ctx.GlobalIsSet("tracker-token") {
+		return ctx.GlobalString("tracker-token")
+	}
+	return os.Getenv(strings.ToUpper(ctx.GlobalString("tracker")) + "_TOKEN")
+}
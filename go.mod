@@ -3,8 +3,13 @@ module github.com/exaring/release-cli
 go 1.13
 
 require (
+	github.com/BurntSushi/toml v0.3.1
 	github.com/go-git/go-git/v5 v5.0.0
 	github.com/sirupsen/logrus v1.6.0
 	github.com/stretchr/testify v1.5.1
 	github.com/urfave/cli v1.22.4
+	golang.org/x/crypto v0.0.0-20200302210943-78000ba7a073
+	golang.org/x/exp v0.0.0-20200331195152-e8c3332aa8e5
+	golang.org/x/mod v0.5.1
+	gopkg.in/yaml.v2 v2.2.4
 )
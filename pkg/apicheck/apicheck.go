@@ -0,0 +1,118 @@
+// Package apicheck compares the exported Go API of two revisions of a module with the compatibility rules of apidiff.
+package apicheck
+
+import (
+	"fmt"
+	"go/token"
+	"sort"
+
+	"github.com/exaring/release-cli/pkg/repository"
+	"github.com/exaring/release-cli/pkg/version"
+	"golang.org/x/exp/apidiff"
+)
+
+// Change is a single difference of the exported API.
+type Change struct {
+	// Package is the import path of the changed package.
+	Package string
+	// Message describes the change.
+	Message string
+	// Compatible reports whether the change is backward compatible.
+	Compatible bool
+}
+
+// Report is the result of the API comparison.
+type Report struct {
+	Changes []Change
+}
+
+// Incompatible returns all changes which break the backward compatibility.
+func (r Report) Incompatible() []Change {
+	return r.filter(false)
+}
+
+// Compatible returns all backward compatible changes.
+func (r Report) Compatible() []Change {
+	return r.filter(true)
+}
+
+func (r Report) filter(compatible bool) []Change {
+	var changes []Change
+	for _, c := range r.Changes {
+		if c.Compatible == compatible {
+			changes = append(changes, c)
+		}
+	}
+	return changes
+}
+
+// RequiredPart returns the version part (version.Major, version.Minor or version.Patch) which has to be increased at
+// least for the reported changes. Incompatible changes of an unstable v0 module only require a minor release.
+func (r Report) RequiredPart(previous version.Version) int {
+	switch {
+	case len(r.Incompatible()) > 0 && previous[version.Major] > 0:
+		return version.Major
+	case len(r.Changes) > 0:
+		return version.Minor
+	default:
+		return version.Patch
+	}
+}
+
+// Compare type-checks the exported packages of the module in both file trees and reports the API changes between them.
+// It returns an error if a package can't be loaded or parsed.
+func Compare(old, new []repository.File) (Report, error) {
+	fset := token.NewFileSet()
+	std := newStdImporter(fset)
+
+	oldMod, err := newModule(old, fset, std)
+	if err != nil {
+		return Report{}, err
+	}
+	newMod, err := newModule(new, fset, std)
+	if err != nil {
+		return Report{}, err
+	}
+
+	oldPkgs, err := oldMod.exported()
+	if err != nil {
+		return Report{}, fmt.Errorf("the previous version: %w", err)
+	}
+	newPkgs, err := newMod.exported()
+	if err != nil {
+		return Report{}, err
+	}
+
+	var paths = make([]string, 0, len(oldPkgs)+len(newPkgs))
+	for p := range oldPkgs {
+		paths = append(paths, p)
+	}
+	for p := range newPkgs {
+		if _, ok := oldPkgs[p]; !ok {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+
+	var report Report
+	for _, p := range paths {
+		oldPkg, inOld := oldPkgs[p]
+		newPkg, inNew := newPkgs[p]
+		switch {
+		case !inNew:
+			report.Changes = append(report.Changes, Change{Package: p, Message: "package removed"})
+		case !inOld:
+			report.Changes = append(report.Changes, Change{Package: p, Message: "package added", Compatible: true})
+		default:
+			for _, c := range apidiff.Changes(oldPkg, newPkg).Changes {
+				report.Changes = append(report.Changes, Change{
+					Package:    p,
+					Message:    c.Message,
+					Compatible: c.Compatible,
+				})
+			}
+		}
+	}
+
+	return report, nil
+}
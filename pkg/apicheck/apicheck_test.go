@@ -0,0 +1,117 @@
+package apicheck
+
+import (
+	"testing"
+
+	"github.com/exaring/release-cli/pkg/repository"
+	"github.com/exaring/release-cli/pkg/version"
+	"github.com/stretchr/testify/assert"
+)
+
+func files(sources map[string]string) []repository.File {
+	var files []repository.File
+	for p, data := range sources {
+		files = append(files, repository.File{Path: p, Mode: 0644, Data: []byte(data)})
+	}
+	return files
+}
+
+func TestCompare(t *testing.T) {
+	base := map[string]string{
+		"go.mod":               "module example.com/m\n",
+		"m.go":                 "package m\n\nimport \"time\"\n\nfunc A(d time.Duration) {}\n\nfunc B() {}\n",
+		"cmd/tool/main.go":     "package main\n\nfunc main() {}\n",
+		"internal/x/x.go":      "package x\n\nfunc X() {}\n",
+		"sub/sub.go":           "package sub\n\nimport \"example.com/m\"\n\nfunc S() { m.B() }\n",
+		"sub/sub_test.go":      "package sub\n\nfunc T() {}\n",
+		"nested/go.mod":        "module example.com/m/nested\n",
+		"nested/nested.go":     "package nested\n\nfunc N() {}\n",
+		"third/third.go":       "package third\n\nimport \"github.com/acme/dep\"\n\nfunc D(dep.Type) {}\n",
+		"testdata/x/broken.go": "package broken\n\nfunc Broken(\n",
+	}
+
+	tt := []struct {
+		name         string
+		change       map[string]string
+		incompatible []string
+		compatible   []string
+	}{
+		{"unchanged", nil, nil, nil},
+		{"removed function", map[string]string{"m.go": "package m\n\nimport \"time\"\n\nfunc A(d time.Duration) {}\n"},
+			[]string{"B: removed"}, nil},
+		{"added function", map[string]string{"sub/sub.go": "package sub\n\nimport \"example.com/m\"\n\nfunc S() { m.B() }\n\nfunc S2() {}\n"},
+			nil, []string{"S2: added"}},
+		{"changed parameter", map[string]string{"m.go": "package m\n\nimport \"time\"\n\nfunc A(d time.Duration, n int) {}\n\nfunc B() {}\n"},
+			[]string{"A: changed from func(time.Duration) to func(time.Duration, int)"}, nil},
+		{"removed package", map[string]string{"sub/sub.go": ""}, []string{"package removed"}, nil},
+		{"internal and command changes", map[string]string{"internal/x/x.go": "package x\n", "cmd/tool/main.go": "package main\n\nfunc main() {}\n\nfunc Y() {}\n", "nested/nested.go": "package nested\n"},
+			nil, nil},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			current := make(map[string]string)
+			for p, data := range base {
+				current[p] = data
+			}
+			for p, data := range tc.change {
+				if data == "" {
+					delete(current, p)
+					continue
+				}
+				current[p] = data
+			}
+
+			report, err := Compare(files(base), files(current))
+			assert.NoError(t, err)
+
+			var incompatible, compatible []string
+			for _, c := range report.Incompatible() {
+				incompatible = append(incompatible, c.Message)
+			}
+			for _, c := range report.Compatible() {
+				compatible = append(compatible, c.Message)
+			}
+			assert.Equal(t, tc.incompatible, incompatible)
+			assert.Equal(t, tc.compatible, compatible)
+		})
+	}
+}
+
+func TestCompare_MissingModFile(t *testing.T) {
+	_, err := Compare(files(map[string]string{"m.go": "package m\n"}), nil)
+	assert.Error(t, err)
+}
+
+func TestCompare_BrokenPackage(t *testing.T) {
+	old := files(map[string]string{"go.mod": "module example.com/m\n", "m.go": "package m\n\nfunc A() {}\n"})
+	broken := files(map[string]string{"go.mod": "module example.com/m\n", "m.go": "package m\n\nfunc A(\n"})
+	mixed := files(map[string]string{"go.mod": "module example.com/m\n", "m.go": "package m\n", "n.go": "package n\n"})
+
+	_, err := Compare(old, broken)
+	assert.Error(t, err, "a package with syntax errors isn't reported as removed")
+	_, err = Compare(broken, old)
+	assert.Error(t, err)
+	_, err = Compare(old, mixed)
+	assert.Error(t, err, "a directory with two packages isn't reported as removed")
+}
+
+func TestReport_RequiredPart(t *testing.T) {
+	incompatible := Report{Changes: []Change{{Message: "B: removed"}, {Message: "C: added", Compatible: true}}}
+	compatible := Report{Changes: []Change{{Message: "C: added", Compatible: true}}}
+
+	tt := []struct {
+		report   Report
+		previous version.Version
+		expected int
+	}{
+		{Report{}, version.Version{1, 2, 3, 0}, version.Patch},
+		{compatible, version.Version{1, 2, 3, 0}, version.Minor},
+		{incompatible, version.Version{1, 2, 3, 0}, version.Major},
+		{incompatible, version.Version{0, 2, 3, 0}, version.Minor},
+	}
+
+	for _, tc := range tt {
+		assert.Equal(t, tc.expected, tc.report.RequiredPart(tc.previous))
+	}
+}
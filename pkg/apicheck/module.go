@@ -0,0 +1,236 @@
+package apicheck
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/exaring/release-cli/pkg/repository"
+	"golang.org/x/mod/modfile"
+)
+
+// module is an in-memory Go module which type-checks its packages on demand.
+type module struct {
+	path    string
+	files   map[string][]byte
+	dirs    map[string][]os.FileInfo
+	ctx     build.Context
+	fset    *token.FileSet
+	std     types.Importer
+	pkgs    map[string]*types.Package
+	loading map[string]bool
+}
+
+// newModule creates a module of the given repository files. The module path is read from the go.mod file.
+func newModule(files []repository.File, fset *token.FileSet, std types.Importer) (*module, error) {
+	m := &module{
+		files:   make(map[string][]byte),
+		dirs:    make(map[string][]os.FileInfo),
+		fset:    fset,
+		std:     std,
+		pkgs:    make(map[string]*types.Package),
+		loading: make(map[string]bool),
+	}
+
+	for _, f := range files {
+		if !f.Mode.IsRegular() {
+			continue
+		}
+		m.files[f.Path] = f.Data
+		dir := path.Dir(f.Path)
+		m.dirs[dir] = append(m.dirs[dir], fileInfo{name: path.Base(f.Path), size: int64(len(f.Data))})
+	}
+
+	goMod, ok := m.files["go.mod"]
+	if !ok {
+		return nil, fmt.Errorf("the revision doesn't contain a go.mod file")
+	}
+	if m.path = modfile.ModulePath(goMod); m.path == "" {
+		return nil, fmt.Errorf("the go.mod file doesn't declare a module path")
+	}
+
+	m.ctx = build.Default
+	m.ctx.GOROOT = ""
+	m.ctx.GOPATH = ""
+	m.ctx.CgoEnabled = false
+	m.ctx.JoinPath = path.Join
+	m.ctx.IsAbsPath = func(string) bool { return false }
+	m.ctx.HasSubdir = func(string, string) (string, bool) { return "", false }
+	m.ctx.IsDir = func(dir string) bool {
+		_, ok := m.dirs[dir]
+		return ok
+	}
+	m.ctx.ReadDir = func(dir string) ([]os.FileInfo, error) {
+		return m.dirs[dir], nil
+	}
+	m.ctx.OpenFile = func(name string) (io.ReadCloser, error) {
+		data, ok := m.files[name]
+		if !ok {
+			return nil, os.ErrNotExist
+		}
+		return ioutil.NopCloser(bytes.NewReader(data)), nil
+	}
+
+	return m, nil
+}
+
+// exported type-checks all packages which are importable by other modules.
+// Commands, internal packages, test data, nested modules and directories without Go files are skipped. It returns an
+// error if a package can't be loaded or parsed, because its API would be reported as removed otherwise.
+func (m *module) exported() (map[string]*types.Package, error) {
+	var dirs = make([]string, 0, len(m.dirs))
+	for dir := range m.dirs {
+		if m.isExported(dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	sort.Strings(dirs)
+
+	var pkgs = make(map[string]*types.Package)
+	for _, dir := range dirs {
+		pkg, err := m.load(m.importPath(dir))
+		var noGo *build.NoGoError
+		switch {
+		case errors.As(err, &noGo):
+			continue
+		case err != nil:
+			return nil, fmt.Errorf("failed to load the package %v: %w", m.importPath(dir), err)
+		case pkg.Name() == "main":
+			continue
+		}
+		pkgs[pkg.Path()] = pkg
+	}
+
+	return pkgs, nil
+}
+
+func (m *module) isExported(dir string) bool {
+	if dir == "." {
+		return true
+	}
+
+	for _, elem := range strings.Split(dir, "/") {
+		switch {
+		case elem == "internal", elem == "testdata", elem == "vendor",
+			strings.HasPrefix(elem, "."), strings.HasPrefix(elem, "_"):
+			return false
+		}
+	}
+
+	for d := dir; d != "."; d = path.Dir(d) {
+		if _, ok := m.files[path.Join(d, "go.mod")]; ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (m *module) importPath(dir string) string {
+	if dir == "." {
+		return m.path
+	}
+	return m.path + "/" + dir
+}
+
+func (m *module) dir(importPath string) (string, bool) {
+	switch {
+	case importPath == m.path:
+		return ".", true
+	case strings.HasPrefix(importPath, m.path+"/"):
+		dir := strings.TrimPrefix(importPath, m.path+"/")
+		_, ok := m.dirs[dir]
+		return dir, ok
+	}
+	return "", false
+}
+
+// Import implements the types.Importer interface. Packages of the module are type-checked from the in-memory files,
+// packages of the standard library from the GOROOT sources and all other packages are replaced by empty placeholders,
+// because they can't be resolved without network access.
+func (m *module) Import(importPath string) (*types.Package, error) {
+	if _, ok := m.dir(importPath); ok {
+		return m.load(importPath)
+	}
+
+	if first := strings.SplitN(importPath, "/", 2)[0]; !strings.Contains(first, ".") && m.std != nil {
+		if pkg, err := m.std.Import(importPath); err == nil {
+			return pkg, nil
+		}
+	}
+
+	pkg := types.NewPackage(importPath, path.Base(importPath))
+	pkg.MarkComplete()
+	return pkg, nil
+}
+
+func (m *module) load(importPath string) (*types.Package, error) {
+	if pkg, ok := m.pkgs[importPath]; ok {
+		return pkg, nil
+	}
+	if m.loading[importPath] {
+		return nil, fmt.Errorf("import cycle via %v", importPath)
+	}
+	m.loading[importPath] = true
+	defer delete(m.loading, importPath)
+
+	dir, _ := m.dir(importPath)
+	bp, err := m.ctx.ImportDir(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(bp.GoFiles) == 0 {
+		return nil, &build.NoGoError{Dir: dir}
+	}
+
+	var files = make([]*ast.File, 0, len(bp.GoFiles))
+	for _, name := range bp.GoFiles {
+		filename := path.Join(dir, name)
+		f, err := parser.ParseFile(m.fset, filename, m.files[filename], 0)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+
+	// type errors are ignored, because they are caused by the placeholders of unresolvable imports
+	conf := types.Config{
+		Importer: m,
+		Error:    func(error) {},
+	}
+	pkg, _ := conf.Check(importPath, m.fset, files, nil)
+	m.pkgs[importPath] = pkg
+
+	return pkg, nil
+}
+
+// newStdImporter creates the importer of the standard library packages.
+func newStdImporter(fset *token.FileSet) types.Importer {
+	return importer.ForCompiler(fset, "source", nil)
+}
+
+// fileInfo is the os.FileInfo of an in-memory file.
+type fileInfo struct {
+	name string
+	size int64
+}
+
+func (fi fileInfo) Name() string       { return fi.name }
+func (fi fileInfo) Size() int64        { return fi.size }
+func (fi fileInfo) Mode() os.FileMode  { return 0644 }
+func (fi fileInfo) ModTime() time.Time { return time.Time{} }
+func (fi fileInfo) IsDir() bool        { return false }
+func (fi fileInfo) Sys() interface{}   { return nil }
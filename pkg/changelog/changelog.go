@@ -0,0 +1,86 @@
+// Package changelog extracts the release notes of a version from a Markdown changelog like the ones of the Keep a
+// Changelog format or renders them from the commit messages of the release.
+package changelog
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/exaring/release-cli/pkg/repository"
+)
+
+// Section returns the notes below the heading of the version, e.g. "## [1.2.3] - 2020-04-12" or "## v1.2.3". The
+// version is given without prefix. The notes end at the next heading of the same or a higher level.
+func Section(data []byte, version string) (string, bool) {
+	heading := regexp.MustCompile(`^(#+)\s+\[?v?` + regexp.QuoteMeta(version) + `\]?(?:\s|$)`)
+
+	var notes []string
+	var level int
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if level == 0 {
+			if m := heading.FindStringSubmatch(line); m != nil {
+				level = len(m[1])
+			}
+			continue
+		}
+
+		if hashes := len(line) - len(strings.TrimLeft(line, "#")); hashes > 0 && hashes <= level &&
+			strings.HasPrefix(line[hashes:], " ") {
+			break
+		}
+		notes = append(notes, line)
+	}
+
+	if level == 0 {
+		return "", false
+	}
+	return strings.TrimSpace(strings.Join(notes, "\n")), true
+}
+
+// FromCommits returns a Markdown list of the subjects of the commits.
+func FromCommits(commits []repository.Commit) string {
+	var b strings.Builder
+	for _, c := range commits {
+		subject := strings.TrimSpace(strings.SplitN(c.Message, "\n", 2)[0])
+		hash := c.Hash
+		if len(hash) > 7 {
+			hash = hash[:7]
+		}
+		fmt.Fprintf(&b, "- %v (%v)\n", subject, hash)
+	}
+	return b.String()
+}
+
+var (
+	versionHeading = regexp.MustCompile(`(?m)^## .*$`)
+	unreleased     = regexp.MustCompile(`(?i)^## \[?unreleased\]?\s*$`)
+)
+
+// Insert adds the section of the version with the notes above the newest version, which is the first heading of the
+// second level except an Unreleased section, e.g. "## [1.2.3] - 2020-04-12". The section is appended if the changelog
+// has no version yet.
+func Insert(data []byte, version string, date time.Time, notes string) []byte {
+	section := fmt.Sprintf("## [%v] - %v\n\n%v\n", version, date.Format("2006-01-02"), strings.TrimSpace(notes))
+	if len(bytes.TrimSpace(data)) == 0 {
+		return []byte("# Changelog\n\n" + section)
+	}
+
+	for _, heading := range versionHeading.FindAllIndex(data, -1) {
+		if unreleased.Match(data[heading[0]:heading[1]]) {
+			continue
+		}
+		var b bytes.Buffer
+		b.Write(data[:heading[0]])
+		b.WriteString(section)
+		b.WriteString("\n")
+		b.Write(data[heading[0]:])
+		return b.Bytes()
+	}
+	return append(append(bytes.TrimRight(data, "\n"), "\n\n"...), section...)
+}
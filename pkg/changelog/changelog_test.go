@@ -0,0 +1,80 @@
+package changelog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/exaring/release-cli/pkg/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+const keepAChangelog = `# Changelog
+
+## [Unreleased]
+
+## [1.3.0] - 2020-04-12
+### Added
+- retractions
+
+## [1.2.3] - 2020-03-01
+### Fixed
+- tags of branches
+`
+
+func TestSection(t *testing.T) {
+	tt := []struct {
+		name     string
+		data     string
+		version  string
+		expected string
+		found    bool
+	}{
+		{"latest", keepAChangelog, "1.3.0", "### Added\n- retractions", true},
+		{"last", keepAChangelog, "1.2.3", "### Fixed\n- tags of branches", true},
+		{"missing", keepAChangelog, "1.2.2", "", false},
+		{"prefix", "# v1.0.0\nfirst\n# v0.9.0\n", "1.0.0", "first", true},
+		{"similar", "## 1.0.10\nten\n", "1.0.1", "", false},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			notes, found := Section([]byte(tc.data), tc.version)
+			assert.Equal(t, tc.found, found)
+			assert.Equal(t, tc.expected, notes)
+		})
+	}
+}
+
+func TestFromCommits(t *testing.T) {
+	assert.Equal(t, "- Fix the tags of branches (3f4c1d8)\n- Add retractions (9c1d2f0)\n", FromCommits([]repository.Commit{
+		{Hash: "3f4c1d8a2b7e", Message: "Fix the tags of branches\n\nThe tags of other branches were ignored."},
+		{Hash: "9c1d2f0e", Message: "Add retractions"},
+	}))
+}
+
+func TestInsert(t *testing.T) {
+	date := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	tt := []struct {
+		name     string
+		data     string
+		expected string
+	}{
+		{"keep a changelog", keepAChangelog, "# Changelog\n\n## [Unreleased]\n\n" +
+			"## [1.4.0] - 2020-05-01\n\n- fragments\n\n## [1.3.0] - 2020-04-12\n### Added\n- retractions\n\n" +
+			"## [1.2.3] - 2020-03-01\n### Fixed\n- tags of branches\n"},
+		{"empty", "", "# Changelog\n\n## [1.4.0] - 2020-05-01\n\n- fragments\n"},
+		{"no version", "# Changelog\n\nAll changes.\n", "# Changelog\n\nAll changes.\n\n" +
+			"## [1.4.0] - 2020-05-01\n\n- fragments\n"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			data := Insert([]byte(tc.data), "1.4.0", date, "- fragments\n")
+			assert.Equal(t, tc.expected, string(data))
+
+			notes, found := Section(data, "1.4.0")
+			assert.True(t, found)
+			assert.Equal(t, "- fragments", notes)
+		})
+	}
+}
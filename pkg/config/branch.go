@@ -0,0 +1,115 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/exaring/release-cli/pkg/version"
+)
+
+// Branch is the release policy of the branches matching the name pattern.
+type Branch struct {
+	// Name is the pattern of the branch names. A * matches any sequence of characters.
+	Name string `yaml:"name" toml:"name" json:"name"`
+	// Channel is the pre-release label of the branches. Branches with a channel only produce pre-releases.
+	Channel string `yaml:"channel,omitempty" toml:"channel,omitempty" json:"channel,omitempty"`
+	// Parts lists the version parts (major, minor, patch or pre) which may be increased. All parts are allowed if it's
+	// empty.
+	Parts []string `yaml:"parts,omitempty" toml:"parts,omitempty" json:"parts,omitempty"`
+}
+
+// UnmarshalYAML allows a plain branch name pattern as shorthand of a branch without restrictions.
+func (b *Branch) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var name string
+	if err := unmarshal(&name); err == nil {
+		*b = Branch{Name: name}
+		return nil
+	}
+
+	type plain Branch
+	return unmarshal((*plain)(b))
+}
+
+// UnmarshalTOML allows a plain branch name pattern as shorthand of a branch without restrictions.
+func (b *Branch) UnmarshalTOML(data interface{}) error {
+	switch v := data.(type) {
+	case string:
+		*b = Branch{Name: v}
+	case map[string]interface{}:
+		*b = Branch{}
+		for key, value := range v {
+			switch key {
+			case "name":
+				b.Name, _ = value.(string)
+			case "channel":
+				b.Channel, _ = value.(string)
+			case "parts":
+				parts, _ := value.([]interface{})
+				for _, p := range parts {
+					part, _ := p.(string)
+					b.Parts = append(b.Parts, part)
+				}
+			default:
+				return fmt.Errorf("unknown key %q of branch", key)
+			}
+		}
+	default:
+		return fmt.Errorf("expected a branch name or table, got %T", data)
+	}
+	return nil
+}
+
+// Validate returns an error if the branch policy is invalid.
+func (b Branch) Validate() error {
+	if _, err := regexp.Compile(globToRegExp(b.Name)); b.Name == "" || err != nil {
+		return fmt.Errorf("invalid branch pattern %q", b.Name)
+	}
+
+	if b.Channel != "" {
+		if err := (version.Format{PreLabel: b.Channel}).Validate(); err != nil {
+			return fmt.Errorf("invalid channel of branch %v: %w", b.Name, err)
+		}
+	}
+
+	for _, part := range b.Parts {
+		if !contains([]string{"major", "minor", "patch", "pre"}, part) {
+			return fmt.Errorf("invalid version part %q of branch %v", part, b.Name)
+		}
+	}
+
+	return nil
+}
+
+// Check returns an error if the branch must not release the next version after the previous version. The final
+// version of a release candidate increases no part, so that the parts don't restrict the promotion of candidates.
+func (b Branch) Check(previous, next version.Version) error {
+	if b.Channel != "" && !next.IsReleaseCandidate() {
+		return fmt.Errorf("the branch %v only produces %v pre-releases, but %v is a final release", b.Name, b.Channel, next)
+	}
+
+	if len(b.Parts) > 0 && !isPromotion(previous, next) {
+		part := version.PartName(version.DifferingPart(previous, next))
+		if !contains(b.Parts, part) {
+			return fmt.Errorf("the branch %v only allows %v releases, but %v is a %v release", b.Name, b.Parts, next, part)
+		}
+	}
+
+	return nil
+}
+
+// isPromotion reports whether the next version is the final version of the previous release candidate.
+func isPromotion(previous, next version.Version) bool {
+	return previous.IsReleaseCandidate() && !next.IsReleaseCandidate() &&
+		version.DifferingPart(previous, next) == version.Pre
+}
+
+// BranchPolicy returns the first branch policy whose pattern matches the branch name. It returns false if no policy
+// matches.
+func BranchPolicy(policies []Branch, branch string) (Branch, bool) {
+	for _, b := range policies {
+		if MatchBranch(b.Name, branch) {
+			return b, true
+		}
+	}
+	return Branch{}, false
+}
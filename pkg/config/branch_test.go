@@ -0,0 +1,59 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/exaring/release-cli/pkg/version"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBranchPolicy(t *testing.T) {
+	policies := []Branch{{Name: "main"}, {Name: "develop", Channel: "beta"}, {Name: "release/*", Parts: []string{"patch"}}}
+
+	tt := []struct {
+		branch   string
+		expected Branch
+		ok       bool
+	}{
+		{"main", policies[0], true},
+		{"develop", policies[1], true},
+		{"release/2.4", policies[2], true},
+		{"feature/x", Branch{}, false},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.branch, func(t *testing.T) {
+			policy, ok := BranchPolicy(policies, tc.branch)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.expected, policy)
+		})
+	}
+}
+
+func TestBranch_Check(t *testing.T) {
+	tt := []struct {
+		name           string
+		policy         Branch
+		previous, next version.Version
+		fails          bool
+	}{
+		{"final", Branch{Name: "main"}, version.Version{1, 2, 3, 0}, version.Version{2, 0, 0, 0}, false},
+		{"channel pre-release", Branch{Name: "develop", Channel: "beta"}, version.Version{1, 2, 3, 0}, version.Version{1, 3, 0, 1}, false},
+		{"channel final", Branch{Name: "develop", Channel: "beta"}, version.Version{1, 3, 0, 1}, version.Version{1, 3, 0, 0}, true},
+		{"allowed part", Branch{Name: "release/*", Parts: []string{"patch"}}, version.Version{2, 4, 7, 0}, version.Version{2, 4, 8, 0}, false},
+		{"disallowed part", Branch{Name: "release/*", Parts: []string{"patch"}}, version.Version{2, 4, 7, 0}, version.Version{2, 5, 0, 0}, true},
+		{"promotion", Branch{Name: "release/*", Parts: []string{"patch"}}, version.Version{2, 5, 0, 2}, version.Version{2, 5, 0, 0}, false},
+		{"disallowed pre-release", Branch{Name: "release/*", Parts: []string{"patch"}}, version.Version{2, 5, 0, 1}, version.Version{2, 5, 0, 2}, true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.policy.Check(tc.previous, tc.next)
+			if tc.fails {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
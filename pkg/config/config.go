@@ -0,0 +1,398 @@
+// Package config loads the project and user configuration of the release tool from .release.yaml or .release.toml
+// files.
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/exaring/release-cli/pkg/notify"
+	"github.com/exaring/release-cli/pkg/version"
+	"github.com/exaring/release-cli/pkg/versionfile"
+)
+
+const (
+	// HookPreCheck runs before the repository is checked.
+	HookPreCheck = "pre-check"
+	// HookPreTag runs before the version tag is created.
+	HookPreTag = "pre-tag"
+	// HookPostTag runs after the version tag is created.
+	HookPostTag = "post-tag"
+	// HookPostPush runs after the version tag is pushed.
+	HookPostPush = "post-push"
+	// HookOnFailure runs if the release fails.
+	HookOnFailure = "on-failure"
+)
+
+// HookNames lists all supported hooks in the order of execution.
+var HookNames = []string{HookPreCheck, HookPreTag, HookPostTag, HookPostPush, HookOnFailure}
+
+// LogLevels lists all supported log levels.
+var LogLevels = []string{"debug", "info", "error"}
+
+// Config is the configuration of the release tool.
+type Config struct {
+	// Tag is the notation of the version tags.
+	Tag Tag `yaml:"tag,omitempty" toml:"tag,omitempty" json:"tag,omitempty"`
+	// Branch limits the tracked tags to the tags of the given branch.
+	Branch string `yaml:"branch,omitempty" toml:"branch,omitempty" json:"branch,omitempty"`
+	// Branches lists the release policies of the branches which may produce releases. All branches are allowed if
+	// it's empty.
+	Branches []Branch `yaml:"branches,omitempty" toml:"branches,omitempty" json:"branches,omitempty"`
+	// BranchTemplate is the name template of maintenance branches with {major} and {minor} placeholders.
+	BranchTemplate string `yaml:"branch-template,omitempty" toml:"branch-template,omitempty" json:"branch-template,omitempty"`
+	// Remote is the name of the remote the release is pushed to.
+	Remote string `yaml:"remote,omitempty" toml:"remote,omitempty" json:"remote,omitempty"`
+	// Backend is the git client, which reads and changes the repository: go-git or exec.
+	Backend string `yaml:"backend,omitempty" toml:"backend,omitempty" json:"backend,omitempty"`
+	// Checks enables the optional checks before tagging.
+	Checks Checks `yaml:"checks,omitempty" toml:"checks,omitempty" json:"checks,omitempty"`
+	// Floating enables the floating alias tags, which are moved to the new release.
+	Floating Floating `yaml:"floating,omitempty" toml:"floating,omitempty" json:"floating,omitempty"`
+	// RemoteLock enables the lock reference on the remote.
+	RemoteLock *bool `yaml:"remote-lock,omitempty" toml:"remote-lock,omitempty" json:"remote-lock,omitempty"`
+	// Retry is the number of times the version is recomputed if the tag already exists on the remote.
+	Retry *int `yaml:"retry,omitempty" toml:"retry,omitempty" json:"retry,omitempty"`
+	// VersionFiles lists the files which store the version. They are updated and committed before tagging.
+	VersionFiles []VersionFile `yaml:"version-files,omitempty" toml:"version-files,omitempty" json:"version-files,omitempty"`
+	// GenVersionFile is the path of the generated Go version file.
+	GenVersionFile string `yaml:"gen-version-file,omitempty" toml:"gen-version-file,omitempty" json:"gen-version-file,omitempty"`
+	// Forge configures the release entries on a code forge.
+	Forge Forge `yaml:"forge,omitempty" toml:"forge,omitempty" json:"forge,omitempty"`
+	// Tracker configures the updates of the issues, which are referenced by the released commits.
+	Tracker Tracker `yaml:"tracker,omitempty" toml:"tracker,omitempty" json:"tracker,omitempty"`
+	// Notify lists the webhooks, which are notified after the release is pushed.
+	Notify []Webhook `yaml:"notify,omitempty" toml:"notify,omitempty" json:"notify,omitempty"`
+	// Changelog configures the release notes.
+	Changelog Changelog `yaml:"changelog,omitempty" toml:"changelog,omitempty" json:"changelog,omitempty"`
+	// Hooks maps the hook names to the commands which are executed around tagging.
+	Hooks map[string][]string `yaml:"hooks,omitempty" toml:"hooks,omitempty" json:"hooks,omitempty"`
+	// Output is the format of the release result.
+	Output string `yaml:"output,omitempty" toml:"output,omitempty" json:"output,omitempty"`
+	// OutputFile is the file the release result is appended to.
+	OutputFile string `yaml:"output-file,omitempty" toml:"output-file,omitempty" json:"output-file,omitempty"`
+	// Log is the log level.
+	Log string `yaml:"log,omitempty" toml:"log,omitempty" json:"log,omitempty"`
+}
+
+// Tag is the notation of the version tags.
+type Tag struct {
+	// Prefix precedes the version numbers, e.g. v.
+	Prefix *string `yaml:"prefix,omitempty" toml:"prefix,omitempty" json:"prefix,omitempty"`
+	// PreLabel is the label of pre-release versions, e.g. RC.
+	PreLabel string `yaml:"pre-label,omitempty" toml:"pre-label,omitempty" json:"pre-label,omitempty"`
+}
+
+// Checks enables the optional checks before tagging.
+type Checks struct {
+	// API enables the API compatibility check against the previous version.
+	API *bool `yaml:"api,omitempty" toml:"api,omitempty" json:"api,omitempty"`
+	// Zip enables the validation of the Go module zip.
+	Zip *bool `yaml:"zip,omitempty" toml:"zip,omitempty" json:"zip,omitempty"`
+}
+
+// Floating enables the floating alias tags, which are moved to the new release.
+type Floating struct {
+	// Aliases enables the alias tags of the major and minor line like v2 and v2.3.
+	Aliases *bool `yaml:"aliases,omitempty" toml:"aliases,omitempty" json:"aliases,omitempty"`
+	// Latest enables the latest alias tag.
+	Latest *bool `yaml:"latest,omitempty" toml:"latest,omitempty" json:"latest,omitempty"`
+}
+
+// VersionFile is a file which stores the version.
+type VersionFile struct {
+	// Path is the path of the file relative to the repository root.
+	Path string `yaml:"path" toml:"path" json:"path"`
+	// Pattern is the regular expression of the version, whose first capture group is the version.
+	Pattern string `yaml:"pattern,omitempty" toml:"pattern,omitempty" json:"pattern,omitempty"`
+	// Key is the dot-separated key path of the version in a YAML or JSON file, e.g. image.tag.
+	Key string `yaml:"key,omitempty" toml:"key,omitempty" json:"key,omitempty"`
+}
+
+// Forge configures the release entries on a code forge. The token is only accepted by flag or environment variable.
+type Forge struct {
+	// Provider is the kind of the forge: github, gitlab or gitea.
+	Provider string `yaml:"provider,omitempty" toml:"provider,omitempty" json:"provider,omitempty"`
+	// URL is the base URL of the API, e.g. https://github.example.com/api/v3.
+	URL string `yaml:"url,omitempty" toml:"url,omitempty" json:"url,omitempty"`
+	// Repository is the path of the repository on the forge. It defaults to the path of the remote URL.
+	Repository string `yaml:"repository,omitempty" toml:"repository,omitempty" json:"repository,omitempty"`
+	// Assets are the glob patterns of the files relative to the repository root, which are uploaded to the release.
+	Assets []string `yaml:"assets,omitempty" toml:"assets,omitempty" json:"assets,omitempty"`
+}
+
+// Tracker configures the updates of the issues, which are referenced by the released commits. The token is only
+// accepted by flag or environment variable.
+type Tracker struct {
+	// Provider is the kind of the issue tracker: github, gitlab or jira.
+	Provider string `yaml:"provider,omitempty" toml:"provider,omitempty" json:"provider,omitempty"`
+	// URL is the base URL of the API, e.g. https://jira.example.com.
+	URL string `yaml:"url,omitempty" toml:"url,omitempty" json:"url,omitempty"`
+	// Repository is the path of the repository of GitHub and GitLab issues. It defaults to the path of the remote URL.
+	Repository string `yaml:"repository,omitempty" toml:"repository,omitempty" json:"repository,omitempty"`
+	// Project is the key of the Jira project, whose issues are updated. Any project matches if it's empty.
+	Project string `yaml:"project,omitempty" toml:"project,omitempty" json:"project,omitempty"`
+	// User is the user of the Jira token. The token is sent as bearer token if it's empty.
+	User string `yaml:"user,omitempty" toml:"user,omitempty" json:"user,omitempty"`
+	// Label is added to GitHub and GitLab issues.
+	Label string `yaml:"label,omitempty" toml:"label,omitempty" json:"label,omitempty"`
+	// FixVersion adds the version to the fix versions of Jira issues.
+	FixVersion *bool `yaml:"fix-version,omitempty" toml:"fix-version,omitempty" json:"fix-version,omitempty"`
+	// Close closes the issues, which are referenced with a closing keyword like Fixes.
+	Close *bool `yaml:"close,omitempty" toml:"close,omitempty" json:"close,omitempty"`
+	// Transition is the name of the Jira transition, which closes an issue, e.g. Done.
+	Transition string `yaml:"transition,omitempty" toml:"transition,omitempty" json:"transition,omitempty"`
+}
+
+// Webhook is notified with a JSON payload after the release is pushed. Environment variables like $SLACK_WEBHOOK in
+// the URL and the headers are expanded, so that secrets aren't stored in the configuration.
+type Webhook struct {
+	// URL is the URL of the webhook.
+	URL string `yaml:"url" toml:"url" json:"url"`
+	// Preset is the payload format of a chat: slack or teams. The default payload is the JSON of the release.
+	Preset string `yaml:"preset,omitempty" toml:"preset,omitempty" json:"preset,omitempty"`
+	// Template is the Go template of the JSON payload, which overrides the preset.
+	Template string `yaml:"template,omitempty" toml:"template,omitempty" json:"template,omitempty"`
+	// Headers are added to the request.
+	Headers map[string]string `yaml:"headers,omitempty" toml:"headers,omitempty" json:"headers,omitempty"`
+	// Timeout limits each attempt, e.g. 10s.
+	Timeout string `yaml:"timeout,omitempty" toml:"timeout,omitempty" json:"timeout,omitempty"`
+	// Retries is the number of times a failed request is repeated.
+	Retries int `yaml:"retries,omitempty" toml:"retries,omitempty" json:"retries,omitempty"`
+}
+
+// Changelog configures the release notes.
+type Changelog struct {
+	// File is the path of the changelog file relative to the repository root.
+	File string `yaml:"file,omitempty" toml:"file,omitempty" json:"file,omitempty"`
+}
+
+// Format returns the tag format of the configuration. Unset values are taken from the default format.
+func (c Config) Format() version.Format {
+	format := version.DefaultFormat
+	if c.Tag.Prefix != nil {
+		format.Prefix = *c.Tag.Prefix
+	}
+	if c.Tag.PreLabel != "" {
+		format.PreLabel = c.Tag.PreLabel
+	}
+	return format
+}
+
+// Validate returns an error if a value of the configuration is invalid.
+func (c Config) Validate() error {
+	if err := c.Format().Validate(); err != nil {
+		return fmt.Errorf("tag: %w", err)
+	}
+
+	for _, b := range c.Branches {
+		if err := b.Validate(); err != nil {
+			return fmt.Errorf("branches: %w", err)
+		}
+	}
+
+	if c.BranchTemplate != "" {
+		if err := version.ValidateBranchTemplate(c.BranchTemplate); err != nil {
+			return fmt.Errorf("branch-template: %w", err)
+		}
+	}
+
+	for _, f := range c.VersionFiles {
+		if f.Path == "" {
+			return fmt.Errorf("version-files: missing path")
+		}
+		if _, err := versionfile.New(f.Path, f.Pattern, f.Key); err != nil {
+			return fmt.Errorf("version-files: %w", err)
+		}
+	}
+
+	if c.Retry != nil && *c.Retry < 0 {
+		return fmt.Errorf("retry: negative number of retries %d", *c.Retry)
+	}
+
+	for name := range c.Hooks {
+		if !contains(HookNames, name) {
+			return fmt.Errorf("hooks: unknown hook %q, expected one of %v", name, HookNames)
+		}
+	}
+
+	if c.Forge.Provider != "" && !contains([]string{"github", "gitlab", "gitea"}, c.Forge.Provider) {
+		return fmt.Errorf("forge: unknown provider %q", c.Forge.Provider)
+	}
+
+	if c.Tracker.Provider != "" && !contains([]string{"github", "gitlab", "jira"}, c.Tracker.Provider) {
+		return fmt.Errorf("tracker: unknown provider %q", c.Tracker.Provider)
+	}
+	if c.Tracker.Provider == "jira" && Enabled(c.Tracker.Close) && c.Tracker.Transition == "" {
+		return fmt.Errorf("tracker: closing Jira issues requires a transition")
+	}
+
+	for _, w := range c.Notify {
+		if w.URL == "" {
+			return fmt.Errorf("notify: missing url")
+		}
+		if err := notify.Check(w.Preset, w.Template); err != nil {
+			return fmt.Errorf("notify: %w", err)
+		}
+		if w.Timeout != "" {
+			if _, err := time.ParseDuration(w.Timeout); err != nil {
+				return fmt.Errorf("notify: invalid timeout: %w", err)
+			}
+		}
+		if w.Retries < 0 {
+			return fmt.Errorf("notify: negative number of retries %d", w.Retries)
+		}
+	}
+
+	if c.Backend != "" && !contains([]string{"go-git", "exec"}, c.Backend) {
+		return fmt.Errorf("backend: unknown git backend %q", c.Backend)
+	}
+
+	if c.Output != "" && !contains([]string{"json", "env", "github", "gitlab"}, c.Output) {
+		return fmt.Errorf("output: unknown output format %q", c.Output)
+	}
+
+	if c.Log != "" && !contains(LogLevels, c.Log) {
+		return fmt.Errorf("log: unknown log level %q, expected one of %v", c.Log, LogLevels)
+	}
+
+	return nil
+}
+
+// Merge returns the configuration with all values of the override configuration which are set.
+func (c Config) Merge(override Config) Config {
+	if override.Tag.Prefix != nil {
+		c.Tag.Prefix = override.Tag.Prefix
+	}
+	if override.Tag.PreLabel != "" {
+		c.Tag.PreLabel = override.Tag.PreLabel
+	}
+	if override.Branch != "" {
+		c.Branch = override.Branch
+	}
+	if len(override.Branches) > 0 {
+		c.Branches = override.Branches
+	}
+	if override.BranchTemplate != "" {
+		c.BranchTemplate = override.BranchTemplate
+	}
+	if override.Remote != "" {
+		c.Remote = override.Remote
+	}
+	if override.Backend != "" {
+		c.Backend = override.Backend
+	}
+	if override.Checks.API != nil {
+		c.Checks.API = override.Checks.API
+	}
+	if override.Checks.Zip != nil {
+		c.Checks.Zip = override.Checks.Zip
+	}
+	if override.Floating.Aliases != nil {
+		c.Floating.Aliases = override.Floating.Aliases
+	}
+	if override.Floating.Latest != nil {
+		c.Floating.Latest = override.Floating.Latest
+	}
+	if override.RemoteLock != nil {
+		c.RemoteLock = override.RemoteLock
+	}
+	if override.Retry != nil {
+		c.Retry = override.Retry
+	}
+	if len(override.VersionFiles) > 0 {
+		c.VersionFiles = override.VersionFiles
+	}
+	if override.GenVersionFile != "" {
+		c.GenVersionFile = override.GenVersionFile
+	}
+	if override.Forge.Provider != "" {
+		c.Forge.Provider = override.Forge.Provider
+	}
+	if override.Forge.URL != "" {
+		c.Forge.URL = override.Forge.URL
+	}
+	if override.Forge.Repository != "" {
+		c.Forge.Repository = override.Forge.Repository
+	}
+	if len(override.Forge.Assets) > 0 {
+		c.Forge.Assets = override.Forge.Assets
+	}
+	if override.Tracker.Provider != "" {
+		c.Tracker.Provider = override.Tracker.Provider
+	}
+	if override.Tracker.URL != "" {
+		c.Tracker.URL = override.Tracker.URL
+	}
+	if override.Tracker.Repository != "" {
+		c.Tracker.Repository = override.Tracker.Repository
+	}
+	if override.Tracker.Project != "" {
+		c.Tracker.Project = override.Tracker.Project
+	}
+	if override.Tracker.User != "" {
+		c.Tracker.User = override.Tracker.User
+	}
+	if override.Tracker.Label != "" {
+		c.Tracker.Label = override.Tracker.Label
+	}
+	if override.Tracker.FixVersion != nil {
+		c.Tracker.FixVersion = override.Tracker.FixVersion
+	}
+	if override.Tracker.Close != nil {
+		c.Tracker.Close = override.Tracker.Close
+	}
+	if override.Tracker.Transition != "" {
+		c.Tracker.Transition = override.Tracker.Transition
+	}
+	if len(override.Notify) > 0 {
+		c.Notify = override.Notify
+	}
+	if override.Changelog.File != "" {
+		c.Changelog.File = override.Changelog.File
+	}
+	if len(override.Hooks) > 0 {
+		hooks := make(map[string][]string, len(c.Hooks)+len(override.Hooks))
+		for name, commands := range c.Hooks {
+			hooks[name] = commands
+		}
+		for name, commands := range override.Hooks {
+			hooks[name] = commands
+		}
+		c.Hooks = hooks
+	}
+	if override.Output != "" {
+		c.Output = override.Output
+	}
+	if override.OutputFile != "" {
+		c.OutputFile = override.OutputFile
+	}
+	if override.Log != "" {
+		c.Log = override.Log
+	}
+	return c
+}
+
+// Enabled reports whether the optional boolean setting is set and true.
+func Enabled(b *bool) bool {
+	return b != nil && *b
+}
+
+// MatchBranch reports whether the branch name matches the pattern. A * matches any sequence of characters.
+func MatchBranch(pattern, branch string) bool {
+	return regexp.MustCompile(globToRegExp(pattern)).MatchString(branch)
+}
+
+func globToRegExp(pattern string) string {
+	return "^" + regexp.MustCompile(`\\\*`).ReplaceAllString(regexp.QuoteMeta(pattern), ".*") + "$"
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
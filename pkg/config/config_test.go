@@ -0,0 +1,141 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/exaring/release-cli/pkg/version"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "release-config")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	prefix, enabled := "api/v", true
+	expected := Config{
+		Tag:      Tag{Prefix: &prefix, PreLabel: "beta"},
+		Branches: []Branch{{Name: "main"}, {Name: "develop", Channel: "beta"}, {Name: "release/*", Parts: []string{"patch"}}},
+		Checks:   Checks{API: &enabled},
+		VersionFiles: []VersionFile{
+			{Path: "VERSION"},
+			{Path: "chart/Chart.yaml", Key: "version"},
+		},
+		Hooks: map[string][]string{HookPreTag: {"make docs"}},
+		Log:   "debug",
+	}
+
+	tt := []struct {
+		name  string
+		data  string
+		fails bool
+	}{
+		{".release.yaml", "tag:\n  prefix: api/v\n  pre-label: beta\n" +
+			"branches:\n  - main\n  - name: develop\n    channel: beta\n  - name: release/*\n    parts: [patch]\n" +
+			"checks:\n  api: true\nversion-files:\n  - path: VERSION\n  - path: chart/Chart.yaml\n    key: version\n" +
+			"hooks:\n  pre-tag: [make docs]\nlog: debug\n", false},
+		{".release.toml", "branches = [{name = \"main\"}, {name = \"develop\", channel = \"beta\"}, " +
+			"{name = \"release/*\", parts = [\"patch\"]}]\nlog = \"debug\"\n[tag]\nprefix = \"api/v\"\n" +
+			"pre-label = \"beta\"\n[checks]\napi = true\n[hooks]\npre-tag = [\"make docs\"]\n" +
+			"[[version-files]]\npath = \"VERSION\"\n[[version-files]]\npath = \"chart/Chart.yaml\"\nkey = \"version\"\n", false},
+		{"unknown.yaml", "tags: {}\n", true},
+		{"unknown.toml", "tags = 1\n", true},
+		{"hook.yaml", "hooks:\n  pre-release: [make]\n", true},
+		{"label.yaml", "tag:\n  pre-label: r.c\n", true},
+		{"log.yaml", "log: trace\n", true},
+		{"channel.yaml", "branches:\n  - name: develop\n    channel: be.ta\n", true},
+		{"parts.yaml", "branches:\n  - name: develop\n    parts: [micro]\n", true},
+		{"branch.yaml", "branches:\n  - name: develop\n    final: true\n", true},
+		{"template.yaml", "branch-template: release/{minor}\n", true},
+		{"retry.yaml", "retry: -1\n", true},
+		{"backend.yaml", "backend: libgit2\n", true},
+		{"forge.yaml", "forge:\n  provider: bitbucket\n", true},
+		{"tracker.yaml", "tracker:\n  provider: redmine\n", true},
+		{"notify.yaml", "notify:\n  - url: $SLACK_WEBHOOK\n    preset: discord\n", true},
+		{"timeout.yaml", "notify:\n  - url: $SLACK_WEBHOOK\n    timeout: 10\n", true},
+		{"transition.yaml", "tracker:\n  provider: jira\n  close: true\n", true},
+		{"files.yaml", "version-files:\n  - path: VERSION\n    pattern: (a)(b)\n", true},
+		{"path.yaml", "version-files:\n  - key: version\n", true},
+		{"config.json", "{}", true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			file := filepath.Join(dir, tc.name)
+			assert.NoError(t, ioutil.WriteFile(file, []byte(tc.data), 0644))
+
+			c, err := ReadFile(file)
+			if tc.fails {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, expected, c)
+		})
+	}
+}
+
+func TestFindFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "release-config")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	sub := filepath.Join(dir, "a", "b")
+	assert.NoError(t, os.MkdirAll(sub, 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, ".release.toml"), nil, 0644))
+
+	assert.Equal(t, filepath.Join(dir, ".release.toml"), FindFile(sub))
+
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "a", ".release.yaml"), nil, 0644))
+	assert.Equal(t, filepath.Join(dir, "a", ".release.yaml"), FindFile(sub))
+
+	// the configuration of a parent directory doesn't belong to a nested repository
+	assert.NoError(t, os.Mkdir(filepath.Join(sub, ".git"), 0755))
+	assert.Equal(t, "", FindFile(sub))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(sub, ".release.yml"), nil, 0644))
+	assert.Equal(t, filepath.Join(sub, ".release.yml"), FindFile(sub))
+}
+
+func TestConfig_Merge(t *testing.T) {
+	empty, enabled, disabled, two, none := "", true, false, 2, 0
+	user := Config{Tag: Tag{PreLabel: "rc"}, Remote: "upstream", Backend: "exec", Log: "debug", RemoteLock: &enabled, Retry: &two,
+		Checks: Checks{API: &enabled}, Hooks: map[string][]string{
+			HookPreTag:   {"make"},
+			HookPostPush: {"notify"},
+		}}
+	project := Config{Tag: Tag{Prefix: &empty}, Log: "error", Retry: &none, Checks: Checks{API: &disabled, Zip: &enabled},
+		Hooks: map[string][]string{
+			HookPreTag: {"make docs"},
+		}}
+
+	assert.Equal(t, Config{
+		Tag:        Tag{Prefix: &empty, PreLabel: "rc"},
+		Remote:     "upstream",
+		Backend:    "exec",
+		Log:        "error",
+		RemoteLock: &enabled,
+		Retry:      &none,
+		Checks:     Checks{API: &disabled, Zip: &enabled},
+		Hooks: map[string][]string{
+			HookPreTag:   {"make docs"},
+			HookPostPush: {"notify"},
+		},
+	}, user.Merge(project))
+}
+
+func TestConfig_Format(t *testing.T) {
+	empty := ""
+	assert.Equal(t, version.DefaultFormat, Config{}.Format())
+	assert.Equal(t, version.Format{Prefix: "", PreLabel: "beta"}, Config{Tag: Tag{Prefix: &empty, PreLabel: "beta"}}.Format())
+}
+
+func TestMatchBranch(t *testing.T) {
+	assert.True(t, MatchBranch("main", "main"))
+	assert.False(t, MatchBranch("main", "maintenance"))
+	assert.True(t, MatchBranch("release/*", "release/2.4"))
+	assert.False(t, MatchBranch("release/*", "feature/release/2.4"))
+	assert.True(t, MatchBranch("*", "feature/x"))
+}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+)
+
+// FileNames lists the names of the project configuration files in the order of their priority.
+var FileNames = []string{".release.yaml", ".release.yml", ".release.toml"}
+
+// UserFile returns the path of the user configuration file, which is the first existing file of
+// $XDG_CONFIG_HOME/release/config.{yaml,yml,toml}. It returns an empty path if no file exists.
+func UserFile() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+
+	for _, name := range []string{"config.yaml", "config.yml", "config.toml"} {
+		file := filepath.Join(dir, "release", name)
+		if _, err := os.Stat(file); err == nil {
+			return file
+		}
+	}
+
+	return ""
+}
+
+// FindFile returns the path of the project configuration file by walking up from the given directory to the top level
+// of the git working tree, which contains the .git directory or file. It returns an empty path if no file exists.
+func FindFile(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+
+	for {
+		for _, name := range FileNames {
+			file := filepath.Join(dir, name)
+			if _, err := os.Stat(file); err == nil {
+				return file
+			}
+		}
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return ""
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// ReadFile reads and validates the configuration file. The format is detected by the file extension. Unknown keys are
+// reported as errors.
+func ReadFile(file string) (Config, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return Config{}, err
+	}
+
+	var c Config
+	switch ext := strings.ToLower(filepath.Ext(file)); ext {
+	case ".yaml", ".yml":
+		if err := yaml.UnmarshalStrict(data, &c); err != nil {
+			return Config{}, fmt.Errorf("%v: %w", file, err)
+		}
+	case ".toml":
+		meta, err := toml.DecodeReader(bytes.NewReader(data), &c)
+		if err != nil {
+			return Config{}, fmt.Errorf("%v: %w", file, err)
+		}
+		var undecoded []toml.Key
+		for _, key := range meta.Undecoded() {
+			// branches are decoded by Branch.UnmarshalTOML, which rejects unknown keys itself
+			if key[0] != "branches" {
+				undecoded = append(undecoded, key)
+			}
+		}
+		if len(undecoded) > 0 {
+			return Config{}, fmt.Errorf("%v: unknown keys %v", file, undecoded)
+		}
+	default:
+		return Config{}, fmt.Errorf("%v: unsupported file extension %q", file, ext)
+	}
+
+	if err := c.Validate(); err != nil {
+		return Config{}, fmt.Errorf("%v: %w", file, err)
+	}
+
+	return c, nil
+}
+
+// Load reads the user configuration and the project configuration found by walking up from the given directory. The
+// values of the project configuration take precedence. It returns the merged configuration and the read files.
+func Load(dir string) (Config, []string, error) {
+	var c Config
+	var files []string
+
+	for _, file := range []string{UserFile(), FindFile(dir)} {
+		if file == "" {
+			continue
+		}
+
+		fc, err := ReadFile(file)
+		if err != nil {
+			return Config{}, nil, err
+		}
+		c = c.Merge(fc)
+		files = append(files, file)
+	}
+
+	return c, files, nil
+}
+
+// Marshal returns the configuration as YAML document.
+func Marshal(c Config) ([]byte, error) {
+	return yaml.Marshal(c)
+}
@@ -0,0 +1,143 @@
+package config
+
+// Schema is the JSON schema of the configuration file, which editors can use for completion and validation.
+const Schema = `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "title": "release-cli configuration",
+  "type": "object",
+  "additionalProperties": false,
+  "properties": {
+    "tag": {
+      "description": "The notation of the version tags.",
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {
+        "prefix": {"description": "Precedes the version numbers, e.g. v or api/v.", "type": "string", "default": "v"},
+        "pre-label": {"description": "The label of pre-release versions.", "type": "string", "pattern": "^[A-Za-z][0-9A-Za-z-]*$", "default": "RC"}
+      }
+    },
+    "branch": {"description": "Only track tags related to the given branch.", "type": "string"},
+    "branches": {
+      "description": "The release policies of the branches which may produce releases. A * matches any sequence of characters.",
+      "type": "array",
+      "items": {
+        "oneOf": [
+          {"type": "string", "minLength": 1},
+          {
+            "type": "object",
+            "additionalProperties": false,
+            "required": ["name"],
+            "properties": {
+              "name": {"description": "The pattern of the branch names.", "type": "string", "minLength": 1},
+              "channel": {"description": "The pre-release label. The branches only produce pre-releases.", "type": "string", "pattern": "^[A-Za-z][0-9A-Za-z-]*$"},
+              "parts": {"description": "The version parts which may be increased.", "type": "array", "items": {"enum": ["major", "minor", "patch", "pre"]}}
+            }
+          }
+        ]
+      }
+    },
+    "branch-template": {"description": "The name template of maintenance branches with {major} and {minor} placeholders.", "type": "string", "pattern": "\\{major\\}", "default": "release/{major}.{minor}"},
+    "remote": {"description": "The name of the remote the release is pushed to.", "type": "string", "default": "origin"},
+    "backend": {"description": "The git client, which reads and changes the repository.", "enum": ["go-git", "exec"], "default": "go-git"},
+    "checks": {
+      "description": "The optional checks before tagging.",
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {
+        "api": {"description": "Compare the exported Go API with the previous version.", "type": "boolean"},
+        "zip": {"description": "Validate the Go module zip of the new version.", "type": "boolean"}
+      }
+    },
+    "remote-lock": {"description": "Lock the release on the remote with the reference refs/release-lock/current.", "type": "boolean"},
+    "retry": {"description": "The number of times the version is recomputed if the tag already exists on the remote.", "type": "integer", "minimum": 0},
+    "floating": {
+      "description": "The floating alias tags, which are moved to the new release.",
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {
+        "aliases": {"description": "Move the alias tags of the major and minor line like v2 and v2.3.", "type": "boolean"},
+        "latest": {"description": "Move the latest alias tag.", "type": "boolean"}
+      }
+    },
+    "version-files": {
+      "description": "The files which store the version. They are updated and committed before tagging.",
+      "type": "array",
+      "items": {
+        "type": "object",
+        "additionalProperties": false,
+        "required": ["path"],
+        "properties": {
+          "path": {"description": "The path of the file relative to the repository root.", "type": "string", "minLength": 1},
+          "pattern": {"description": "The regular expression of the version, whose first capture group is the version.", "type": "string"},
+          "key": {"description": "The dot-separated key path of the version in a YAML or JSON file.", "type": "string"}
+        },
+        "not": {"required": ["pattern", "key"]}
+      }
+    },
+    "gen-version-file": {"description": "The path of the generated Go version file, which is committed before tagging.", "type": "string", "pattern": "\\.go$"},
+    "forge": {
+      "description": "The release entries on a code forge. The token is passed by --forge-token or RELEASE_FORGE_TOKEN.",
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {
+        "provider": {"description": "The kind of the forge.", "enum": ["github", "gitlab", "gitea"]},
+        "url": {"description": "The base URL of the API.", "type": "string", "format": "uri"},
+        "repository": {"description": "The path of the repository on the forge. Defaults to the path of the remote URL.", "type": "string"},
+        "assets": {"description": "The glob patterns of the files, which are uploaded to the release.", "type": "array", "items": {"type": "string"}}
+      }
+    },
+    "tracker": {
+      "description": "The updates of the issues, which are referenced by the released commits. The token is passed by --tracker-token or RELEASE_TRACKER_TOKEN.",
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {
+        "provider": {"description": "The kind of the issue tracker.", "enum": ["github", "gitlab", "jira"]},
+        "url": {"description": "The base URL of the API.", "type": "string", "format": "uri"},
+        "repository": {"description": "The path of the repository of GitHub and GitLab issues. Defaults to the path of the remote URL.", "type": "string"},
+        "project": {"description": "The key of the Jira project, whose issues are updated.", "type": "string"},
+        "user": {"description": "The user of the Jira token.", "type": "string"},
+        "label": {"description": "The label, which is added to GitHub and GitLab issues.", "type": "string"},
+        "fix-version": {"description": "Add the version to the fix versions of Jira issues.", "type": "boolean"},
+        "close": {"description": "Close the issues, which are referenced with a closing keyword like Fixes.", "type": "boolean"},
+        "transition": {"description": "The name of the Jira transition, which closes an issue.", "type": "string"}
+      }
+    },
+    "notify": {
+      "description": "The webhooks, which are notified after the release is pushed. Environment variables in the URL and the headers are expanded.",
+      "type": "array",
+      "items": {
+        "type": "object",
+        "additionalProperties": false,
+        "required": ["url"],
+        "properties": {
+          "url": {"description": "The URL of the webhook, e.g. $SLACK_WEBHOOK.", "type": "string", "minLength": 1},
+          "preset": {"description": "The payload format of a chat. The default payload is the JSON of the release.", "enum": ["slack", "teams"]},
+          "template": {"description": "The Go template of the JSON payload, which overrides the preset.", "type": "string"},
+          "headers": {"description": "The headers of the request.", "type": "object", "additionalProperties": {"type": "string"}},
+          "timeout": {"description": "The timeout of each attempt, e.g. 10s.", "type": "string"},
+          "retries": {"description": "The number of times a failed request is repeated.", "type": "integer", "minimum": 0}
+        }
+      }
+    },
+    "changelog": {
+      "description": "The release notes.",
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {
+        "file": {"description": "The path of the changelog file.", "type": "string"}
+      }
+    },
+    "hooks": {
+      "description": "The commands which are executed around tagging.",
+      "type": "object",
+      "additionalProperties": false,
+      "patternProperties": {
+        "^(pre-check|pre-tag|post-tag|post-push|on-failure)$": {"type": "array", "items": {"type": "string"}}
+      }
+    },
+    "output": {"description": "The format of the release result.", "enum": ["json", "env", "github", "gitlab"]},
+    "output-file": {"description": "The file the release result is appended to.", "type": "string"},
+    "log": {"description": "The log level.", "enum": ["debug", "info", "error"]}
+  }
+}
+`
@@ -0,0 +1,91 @@
+// Package forge creates or updates the release entries of tags on code forges like GitHub, GitLab and Gitea.
+package forge
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const (
+	// GitHub is the provider of GitHub and GitHub Enterprise.
+	GitHub = "github"
+	// GitLab is the provider of GitLab.
+	GitLab = "gitlab"
+	// Gitea is the provider of Gitea and Forgejo.
+	Gitea = "gitea"
+)
+
+// Providers lists all supported providers.
+var Providers = []string{GitHub, GitLab, Gitea}
+
+// Release is the release entry of a tag.
+type Release struct {
+	// Tag is the name of the released tag.
+	Tag string
+	// Name is the title of the release.
+	Name string
+	// Notes are the release notes in Markdown.
+	Notes string
+	// Prerelease marks the release as pre-release.
+	Prerelease bool
+	// Assets are the paths of the local files, which are uploaded to the release.
+	Assets []string
+}
+
+// Provider creates or updates release entries on a forge.
+type Provider interface {
+	// Publish creates the release of the tag or updates the existing release of the tag and uploads the assets.
+	// Existing assets with the same name are replaced. It returns the web URL of the release.
+	Publish(ctx context.Context, r Release) (string, error)
+}
+
+// Options configure a provider.
+type Options struct {
+	// BaseURL is the URL of the API, e.g. https://api.github.com. It defaults to the API of the public instance.
+	BaseURL string
+	// Token authenticates the requests.
+	Token string
+	// Repository is the path of the repository, e.g. exaring/release-cli.
+	Repository string
+	// Client sends the requests. It defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+// New returns the provider of the given kind.
+func New(kind string, opts Options) (Provider, error) {
+	if opts.Repository == "" {
+		return nil, fmt.Errorf("the repository of the %v release is unknown", kind)
+	}
+
+	switch kind {
+	case GitHub:
+		return newGitHub(opts), nil
+	case GitLab:
+		return newGitLab(opts), nil
+	case Gitea:
+		return newGitea(opts), nil
+	default:
+		return nil, fmt.Errorf("unknown forge %q, expected one of %v", kind, Providers)
+	}
+}
+
+// RepositoryPath returns the path of the repository of a remote URL like git@github.com:exaring/release-cli.git or
+// https://gitlab.com/group/sub/project.git.
+func RepositoryPath(remoteURL string) (string, error) {
+	var path string
+	if u, err := url.Parse(remoteURL); err == nil && u.Scheme != "" && u.Host != "" {
+		path = u.Path
+	} else if m := regexp.MustCompile(`^(?:[^@/]+@)?[^:/]+:(.+)$`).FindStringSubmatch(remoteURL); m != nil {
+		path = m[1]
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if !strings.Contains(path, "/") {
+		return "", fmt.Errorf("the repository of the remote URL %v is unknown", remoteURL)
+	}
+	return path, nil
+}
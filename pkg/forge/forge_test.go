@@ -0,0 +1,167 @@
+package forge
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeForge answers the requests with the responses of "METHOD /path" keys and records the requests. Unknown
+// requests are answered with 404. {{URL}} in the responses is replaced by the URL of the server.
+type fakeForge struct {
+	*httptest.Server
+	responses map[string]string
+	requests  []string
+	headers   []http.Header
+}
+
+func newFakeForge(responses map[string]string) *fakeForge {
+	f := &fakeForge{responses: responses}
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Method + " " + r.URL.EscapedPath()
+		f.requests = append(f.requests, key)
+		f.headers = append(f.headers, r.Header)
+
+		response, ok := f.responses[key]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(strings.Replace(response, "{{URL}}", f.URL, -1)))
+	}))
+	return f
+}
+
+func TestProviders(t *testing.T) {
+	dir, err := ioutil.TempDir("", "release-forge")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	asset := filepath.Join(dir, "app.tar.gz")
+	assert.NoError(t, ioutil.WriteFile(asset, []byte("binary"), 0644))
+
+	release := Release{Tag: "v1.3.0", Name: "v1.3.0", Notes: "- retractions", Assets: []string{asset}}
+
+	tt := []struct {
+		name      string
+		kind      string
+		responses map[string]string
+		requests  []string
+		auth      string
+		url       string
+	}{
+		{
+			name: "github create",
+			kind: GitHub,
+			responses: map[string]string{
+				"POST /repos/acme/app/releases": `{"id": 1, "html_url": "https://github.com/acme/app/releases/v1.3.0", ` +
+					`"upload_url": "{{URL}}/upload/1/assets{?name,label}"}`,
+				"POST /upload/1/assets": `{}`,
+			},
+			requests: []string{"GET /repos/acme/app/releases/tags/v1.3.0", "POST /repos/acme/app/releases",
+				"POST /upload/1/assets"},
+			auth: "Authorization=token secret",
+			url:  "https://github.com/acme/app/releases/v1.3.0",
+		},
+		{
+			name: "github update",
+			kind: GitHub,
+			responses: map[string]string{
+				"GET /repos/acme/app/releases/tags/v1.3.0": `{"id": 1, "assets": [{"id": 7, "name": "app.tar.gz"}]}`,
+				"PATCH /repos/acme/app/releases/1": `{"id": 1, "html_url": "https://github.com/acme/app/releases/v1.3.0", ` +
+					`"upload_url": "{{URL}}/upload/1/assets{?name,label}", "assets": [{"id": 7, "name": "app.tar.gz"}]}`,
+				"DELETE /repos/acme/app/releases/assets/7": ``,
+				"POST /upload/1/assets":                    `{}`,
+			},
+			requests: []string{"GET /repos/acme/app/releases/tags/v1.3.0", "PATCH /repos/acme/app/releases/1",
+				"DELETE /repos/acme/app/releases/assets/7", "POST /upload/1/assets"},
+			auth: "Authorization=token secret",
+			url:  "https://github.com/acme/app/releases/v1.3.0",
+		},
+		{
+			name: "gitea create",
+			kind: Gitea,
+			responses: map[string]string{
+				"POST /repos/acme/app/releases":          `{"id": 2, "html_url": "https://gitea.com/acme/app/releases/tag/v1.3.0"}`,
+				"POST /repos/acme/app/releases/2/assets": `{}`,
+			},
+			requests: []string{"GET /repos/acme/app/releases/tags/v1.3.0", "POST /repos/acme/app/releases",
+				"POST /repos/acme/app/releases/2/assets"},
+			auth: "Authorization=token secret",
+			url:  "https://gitea.com/acme/app/releases/tag/v1.3.0",
+		},
+		{
+			name: "gitlab update",
+			kind: GitLab,
+			responses: map[string]string{
+				"GET /projects/acme%2Fapp/releases/v1.3.0": `{"assets": {"links": [{"id": 3, "name": "app.tar.gz"}]}}`,
+				"PUT /projects/acme%2Fapp/releases/v1.3.0": `{"_links": {"self": "https://gitlab.com/acme/app/-/releases/v1.3.0"}, ` +
+					`"assets": {"links": [{"id": 3, "name": "app.tar.gz"}]}}`,
+				"DELETE /projects/acme%2Fapp/releases/v1.3.0/assets/links/3": `{}`,
+				"POST /projects/acme%2Fapp/uploads":                          `{"url": "/uploads/abc/app.tar.gz"}`,
+				"POST /projects/acme%2Fapp/releases/v1.3.0/assets/links":     `{}`,
+			},
+			requests: []string{"GET /projects/acme%2Fapp/releases/v1.3.0", "PUT /projects/acme%2Fapp/releases/v1.3.0",
+				"DELETE /projects/acme%2Fapp/releases/v1.3.0/assets/links/3", "POST /projects/acme%2Fapp/uploads",
+				"POST /projects/acme%2Fapp/releases/v1.3.0/assets/links"},
+			auth: "Private-Token=secret",
+			url:  "https://gitlab.com/acme/app/-/releases/v1.3.0",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			server := newFakeForge(tc.responses)
+			defer server.Close()
+
+			provider, err := New(tc.kind, Options{BaseURL: server.URL, Token: "secret", Repository: "acme/app"})
+			assert.NoError(t, err)
+
+			url, err := provider.Publish(context.Background(), release)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.url, url)
+			assert.Equal(t, tc.requests, server.requests)
+
+			auth := strings.SplitN(tc.auth, "=", 2)
+			for _, h := range server.headers {
+				assert.Equal(t, auth[1], h.Get(auth[0]))
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	_, err := New("bitbucket", Options{Repository: "acme/app"})
+	assert.Error(t, err)
+
+	_, err = New(GitHub, Options{})
+	assert.Error(t, err)
+}
+
+func TestRepositoryPath(t *testing.T) {
+	tt := []struct {
+		url      string
+		expected string
+	}{
+		{"git@github.com:exaring/release-cli.git", "exaring/release-cli"},
+		{"https://github.com/exaring/release-cli", "exaring/release-cli"},
+		{"ssh://git@gitlab.example.com:2222/group/sub/project.git", "group/sub/project"},
+		{"gitea.example.com:acme/app.git", "acme/app"},
+	}
+
+	for _, tc := range tt {
+		path, err := RepositoryPath(tc.url)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expected, path)
+	}
+
+	_, err := RepositoryPath("/tmp/remote.git")
+	assert.Error(t, err)
+}
@@ -0,0 +1,100 @@
+package forge
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/exaring/release-cli/pkg/restapi"
+)
+
+// gitea creates releases with the API of Gitea, which resembles the GitHub API.
+type gitea struct {
+	restapi.Client
+	repository string
+}
+
+func newGitea(opts Options) *gitea {
+	if opts.BaseURL == "" {
+		opts.BaseURL = "https://gitea.com/api/v1"
+	}
+	return &gitea{
+		Client: restapi.Client{
+			BaseURL: opts.BaseURL,
+			Auth:    restapi.TokenAuth("Authorization", "token ", opts.Token),
+			HTTP:    opts.Client,
+		},
+		repository: opts.Repository,
+	}
+}
+
+// Publish creates or updates the release of the tag.
+func (g *gitea) Publish(ctx context.Context, r Release) (string, error) {
+	base := "/repos/" + g.repository + "/releases"
+	body := gitHubRelease{TagName: r.Tag, Name: r.Name, Body: r.Notes, Prerelease: r.Prerelease}
+
+	var release gitHubRelease
+	err := g.Do(ctx, http.MethodGet, base+"/tags/"+url.PathEscape(r.Tag), nil, &release)
+	switch {
+	case err == restapi.ErrNotFound:
+		err = g.Do(ctx, http.MethodPost, base, body, &release)
+	case err == nil:
+		err = g.Do(ctx, http.MethodPatch, fmt.Sprintf("%v/%d", base, release.ID), body, &release)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to publish the Gitea release: %w", err)
+	}
+
+	for _, asset := range r.Assets {
+		name := filepath.Base(asset)
+		for _, existing := range release.Assets {
+			if existing.Name != name {
+				continue
+			}
+			path := fmt.Sprintf("%v/%d/assets/%d", base, release.ID, existing.ID)
+			if err := g.Do(ctx, http.MethodDelete, path, nil, nil); err != nil {
+				return "", fmt.Errorf("failed to replace the Gitea release asset %v: %w", name, err)
+			}
+		}
+
+		body, err := multipartFile("attachment", asset)
+		if err != nil {
+			return "", err
+		}
+		path := fmt.Sprintf("%v/%d/assets?name=%v", base, release.ID, url.QueryEscape(name))
+		if err := g.Do(ctx, http.MethodPost, path, body, nil); err != nil {
+			return "", fmt.Errorf("failed to upload the Gitea release asset %v: %w", name, err)
+		}
+	}
+
+	return release.HTMLURL, nil
+}
+
+// multipartFile returns a multipart form with the file in the given field.
+func multipartFile(field, path string) (restapi.Upload, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return restapi.Upload{}, err
+	}
+	defer f.Close()
+
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	part, err := w.CreateFormFile(field, filepath.Base(path))
+	if err != nil {
+		return restapi.Upload{}, err
+	}
+	if _, err := io.Copy(part, f); err != nil {
+		return restapi.Upload{}, err
+	}
+	if err := w.Close(); err != nil {
+		return restapi.Upload{}, err
+	}
+	return restapi.Upload{Body: &buf, ContentType: w.FormDataContentType()}, nil
+}
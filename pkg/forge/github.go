@@ -0,0 +1,97 @@
+package forge
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/exaring/release-cli/pkg/restapi"
+)
+
+// gitHub creates releases with the REST API of GitHub.
+type gitHub struct {
+	restapi.Client
+	repository string
+}
+
+// gitHubRelease is a release of the GitHub API.
+type gitHubRelease struct {
+	ID         int64  `json:"id,omitempty"`
+	TagName    string `json:"tag_name"`
+	Name       string `json:"name"`
+	Body       string `json:"body"`
+	Prerelease bool   `json:"prerelease"`
+	HTMLURL    string `json:"html_url,omitempty"`
+	UploadURL  string `json:"upload_url,omitempty"`
+	Assets     []struct {
+		ID   int64  `json:"id"`
+		Name string `json:"name"`
+	} `json:"assets,omitempty"`
+}
+
+func newGitHub(opts Options) *gitHub {
+	if opts.BaseURL == "" {
+		opts.BaseURL = "https://api.github.com"
+	}
+	return &gitHub{
+		Client: restapi.Client{
+			BaseURL: opts.BaseURL,
+			Auth:    restapi.TokenAuth("Authorization", "token ", opts.Token),
+			HTTP:    opts.Client,
+		},
+		repository: opts.Repository,
+	}
+}
+
+// Publish creates or updates the release of the tag.
+func (g *gitHub) Publish(ctx context.Context, r Release) (string, error) {
+	base := "/repos/" + g.repository + "/releases"
+	body := gitHubRelease{TagName: r.Tag, Name: r.Name, Body: r.Notes, Prerelease: r.Prerelease}
+
+	var release gitHubRelease
+	err := g.Do(ctx, http.MethodGet, base+"/tags/"+url.PathEscape(r.Tag), nil, &release)
+	switch {
+	case err == restapi.ErrNotFound:
+		err = g.Do(ctx, http.MethodPost, base, body, &release)
+	case err == nil:
+		err = g.Do(ctx, http.MethodPatch, fmt.Sprintf("%v/%d", base, release.ID), body, &release)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to publish the GitHub release: %w", err)
+	}
+
+	for _, asset := range r.Assets {
+		name := filepath.Base(asset)
+		for _, existing := range release.Assets {
+			if existing.Name != name {
+				continue
+			}
+			if err := g.Do(ctx, http.MethodDelete, fmt.Sprintf("%v/assets/%d", base, existing.ID), nil, nil); err != nil {
+				return "", fmt.Errorf("failed to replace the GitHub release asset %v: %w", name, err)
+			}
+		}
+
+		// the upload requires the content length, which is set for in-memory bodies
+		data, err := ioutil.ReadFile(asset)
+		if err != nil {
+			return "", err
+		}
+		// the upload URL is a URI template like https://uploads.github.com/repos/o/r/releases/1/assets{?name,label}
+		uploadURL := release.UploadURL
+		if i := strings.Index(uploadURL, "{"); i >= 0 {
+			uploadURL = uploadURL[:i]
+		}
+		err = g.Do(ctx, http.MethodPost, uploadURL+"?name="+url.QueryEscape(name),
+			restapi.Upload{Body: bytes.NewReader(data), ContentType: "application/octet-stream"}, nil)
+		if err != nil {
+			return "", fmt.Errorf("failed to upload the GitHub release asset %v: %w", name, err)
+		}
+	}
+
+	return release.HTMLURL, nil
+}
@@ -0,0 +1,123 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/exaring/release-cli/pkg/restapi"
+)
+
+// gitLab creates releases with the REST API of GitLab.
+type gitLab struct {
+	restapi.Client
+	project string
+}
+
+// gitLabRelease is a release of the GitLab API.
+type gitLabRelease struct {
+	TagName     string `json:"tag_name,omitempty"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Links       struct {
+		Self string `json:"self"`
+	} `json:"_links,omitempty"`
+	Assets struct {
+		Links []gitLabLink `json:"links"`
+	} `json:"assets,omitempty"`
+}
+
+// gitLabLink is an asset link of a release.
+type gitLabLink struct {
+	ID   int64  `json:"id,omitempty"`
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+func newGitLab(opts Options) *gitLab {
+	if opts.BaseURL == "" {
+		opts.BaseURL = "https://gitlab.com/api/v4"
+	}
+	return &gitLab{
+		Client: restapi.Client{
+			BaseURL: opts.BaseURL,
+			Auth:    restapi.TokenAuth("PRIVATE-TOKEN", "", opts.Token),
+			HTTP:    opts.Client,
+		},
+		project: url.PathEscape(opts.Repository),
+	}
+}
+
+// Publish creates or updates the release of the tag. GitLab has no pre-release flag, so the name of pre-releases is
+// marked instead. The assets are uploaded to the project and linked to the release.
+func (g *gitLab) Publish(ctx context.Context, r Release) (string, error) {
+	base := "/projects/" + g.project + "/releases"
+	tagPath := base + "/" + url.PathEscape(r.Tag)
+	body := gitLabRelease{Name: r.Name, Description: r.Notes}
+	if r.Prerelease {
+		body.Name += " (pre-release)"
+	}
+
+	var release gitLabRelease
+	err := g.Do(ctx, http.MethodGet, tagPath, nil, &release)
+	switch {
+	case err == restapi.ErrNotFound:
+		body.TagName = r.Tag
+		err = g.Do(ctx, http.MethodPost, base, body, &release)
+	case err == nil:
+		err = g.Do(ctx, http.MethodPut, tagPath, body, &release)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to publish the GitLab release: %w", err)
+	}
+
+	for _, asset := range r.Assets {
+		name := filepath.Base(asset)
+		for _, existing := range release.Assets.Links {
+			if existing.Name != name {
+				continue
+			}
+			path := fmt.Sprintf("%v/assets/links/%d", tagPath, existing.ID)
+			if err := g.Do(ctx, http.MethodDelete, path, nil, nil); err != nil {
+				return "", fmt.Errorf("failed to replace the GitLab release asset %v: %w", name, err)
+			}
+		}
+
+		body, err := multipartFile("file", asset)
+		if err != nil {
+			return "", err
+		}
+		var uploaded struct {
+			URL      string `json:"url"`
+			FullPath string `json:"full_path"`
+		}
+		if err := g.Do(ctx, http.MethodPost, "/projects/"+g.project+"/uploads", body, &uploaded); err != nil {
+			return "", fmt.Errorf("failed to upload the GitLab release asset %v: %w", name, err)
+		}
+
+		link := gitLabLink{Name: name, URL: g.absoluteURL(uploaded.FullPath, uploaded.URL)}
+		if err := g.Do(ctx, http.MethodPost, tagPath+"/assets/links", link, nil); err != nil {
+			return "", fmt.Errorf("failed to link the GitLab release asset %v: %w", name, err)
+		}
+	}
+
+	return release.Links.Self, nil
+}
+
+// absoluteURL returns the URL of an upload. Newer GitLab versions return the full path of the upload, older ones only
+// the path relative to the project.
+func (g *gitLab) absoluteURL(fullPath, relative string) string {
+	u, err := url.Parse(g.BaseURL)
+	if err != nil {
+		return relative
+	}
+	host := u.Scheme + "://" + u.Host
+	if fullPath != "" {
+		return host + fullPath
+	}
+	project, _ := url.PathUnescape(g.project)
+	return host + "/" + project + "/" + strings.TrimPrefix(relative, "/")
+}
@@ -0,0 +1,194 @@
+// Package fragment reads the change fragments of a release, which are Markdown files with the bump type of the change
+// in their front matter, and renders them as release notes:
+//
+//	---
+//	bump: minor
+//	---
+//	Retract releases with `release retract`.
+package fragment
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Dir is the directory of the fragments relative to the repository root.
+const Dir = ".changes"
+
+const (
+	// Major is the bump type of incompatible changes.
+	Major = "major"
+	// Minor is the bump type of new features.
+	Minor = "minor"
+	// Patch is the bump type of fixes.
+	Patch = "patch"
+)
+
+// Bumps lists the bump types from the largest to the smallest one.
+var Bumps = []string{Major, Minor, Patch}
+
+// headings are the headings of the bump types in the release notes.
+var headings = map[string]string{
+	Major: "Major Changes",
+	Minor: "Minor Changes",
+	Patch: "Patch Changes",
+}
+
+// Fragment is a change of the release.
+type Fragment struct {
+	// Path is the path of the file relative to the repository root.
+	Path string
+	// Bump is the bump type of the change.
+	Bump string
+	// Body is the Markdown description of the change.
+	Body string
+}
+
+// Parse parses the front matter and the body of a fragment.
+func Parse(path string, data []byte) (Fragment, error) {
+	data = bytes.Replace(data, []byte("\r\n"), []byte("\n"), -1)
+	parts := bytes.SplitN(data, []byte("---\n"), 3)
+	if len(parts) != 3 || len(bytes.TrimSpace(parts[0])) > 0 {
+		return Fragment{}, fmt.Errorf("the fragment %v has no front matter", path)
+	}
+
+	var front struct {
+		Bump string `yaml:"bump"`
+	}
+	if err := yaml.UnmarshalStrict(parts[1], &front); err != nil {
+		return Fragment{}, fmt.Errorf("invalid front matter of the fragment %v: %w", path, err)
+	}
+	if rank(front.Bump) < 0 {
+		return Fragment{}, fmt.Errorf("unknown bump %q of the fragment %v, expected one of %v", front.Bump, path, Bumps)
+	}
+
+	body := strings.TrimSpace(string(parts[2]))
+	if body == "" {
+		return Fragment{}, fmt.Errorf("the fragment %v has no description", path)
+	}
+	return Fragment{Path: path, Bump: front.Bump, Body: body}, nil
+}
+
+// Read returns the fragments of the repository in the order of their file names. The Markdown files of the fragment
+// directory except a README.md are fragments. It returns no fragments if the directory doesn't exist.
+func Read(root string) ([]Fragment, error) {
+	files, err := ioutil.ReadDir(filepath.Join(root, Dir))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the fragments: %w", err)
+	}
+
+	var fragments []Fragment
+	for _, f := range files {
+		name := f.Name()
+		if f.IsDir() || filepath.Ext(name) != ".md" || strings.EqualFold(name, "README.md") ||
+			strings.HasPrefix(name, ".") {
+			continue
+		}
+
+		path := filepath.ToSlash(filepath.Join(Dir, name))
+		data, err := ioutil.ReadFile(filepath.Join(root, path))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the fragment: %w", err)
+		}
+		fragment, err := Parse(path, data)
+		if err != nil {
+			return nil, err
+		}
+		fragments = append(fragments, fragment)
+	}
+	return fragments, nil
+}
+
+// Bump returns the largest bump type of the fragments or an empty string if there are no fragments.
+func Bump(fragments []Fragment) string {
+	var bump string
+	for _, f := range fragments {
+		if bump == "" || rank(f.Bump) < rank(bump) {
+			bump = f.Bump
+		}
+	}
+	return bump
+}
+
+// Notes renders the fragments as Markdown list below a heading of each bump type, starting with the largest one.
+// Multi-line descriptions are indented below their list item.
+func Notes(fragments []Fragment) string {
+	var sorted = make([]Fragment, len(fragments))
+	copy(sorted, fragments)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return rank(sorted[i].Bump) < rank(sorted[j].Bump)
+	})
+
+	var b strings.Builder
+	for i, f := range sorted {
+		if i == 0 || sorted[i-1].Bump != f.Bump {
+			if i > 0 {
+				b.WriteString("\n")
+			}
+			fmt.Fprintf(&b, "### %v\n\n", headings[f.Bump])
+		}
+		for j, line := range strings.Split(f.Body, "\n") {
+			switch {
+			case j == 0:
+				fmt.Fprintf(&b, "- %v\n", line)
+			case line == "":
+				b.WriteString("\n")
+			default:
+				fmt.Fprintf(&b, "  %v\n", line)
+			}
+		}
+	}
+	return b.String()
+}
+
+// New returns the path and the content of a new fragment. The file name starts with the time to keep the fragments in
+// the order of their creation and ends with the first words of the description.
+func New(bump, body string, now time.Time) (string, []byte, error) {
+	body = strings.TrimSpace(body)
+	if rank(bump) < 0 {
+		return "", nil, fmt.Errorf("unknown bump %q, expected one of %v", bump, Bumps)
+	}
+	if body == "" {
+		return "", nil, fmt.Errorf("the description is empty")
+	}
+
+	name := now.UTC().Format("20060102-150405")
+	if slug := slug(strings.SplitN(body, "\n", 2)[0]); slug != "" {
+		name += "-" + slug
+	}
+	data := fmt.Sprintf("---\nbump: %v\n---\n%v\n", bump, body)
+	return filepath.ToSlash(filepath.Join(Dir, name+".md")), []byte(data), nil
+}
+
+var nonWord = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slug returns the lower-case words of the text joined by dashes. It's limited to 40 characters.
+func slug(text string) string {
+	s := strings.Trim(nonWord.ReplaceAllString(strings.ToLower(text), "-"), "-")
+	if len(s) > 40 {
+		s = strings.TrimRight(s[:40], "-")
+	}
+	return s
+}
+
+// rank returns the position of the bump type in Bumps or -1 for unknown bump types.
+func rank(bump string) int {
+	for i, b := range Bumps {
+		if b == bump {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,102 @@
+package fragment
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tt := []struct {
+		name     string
+		data     string
+		expected Fragment
+		err      string
+	}{
+		{"minor", "---\nbump: minor\n---\nRetract releases.\n", Fragment{Path: "a.md", Bump: Minor, Body: "Retract releases."}, ""},
+		{"crlf", "---\r\nbump: patch\r\n---\r\nFix tags.\r\n", Fragment{Path: "a.md", Bump: Patch, Body: "Fix tags."}, ""},
+		{"no front matter", "Fix tags.\n", Fragment{}, "the fragment a.md has no front matter"},
+		{"unknown bump", "---\nbump: feature\n---\nFix tags.\n", Fragment{},
+			`unknown bump "feature" of the fragment a.md, expected one of [major minor patch]`},
+		{"unknown key", "---\nbump: patch\ntype: fix\n---\nFix tags.\n", Fragment{},
+			"invalid front matter of the fragment a.md: yaml: unmarshal errors:\n  line 2: field type not found in type struct { Bump string \"yaml:\\\"bump\\\"\" }"},
+		{"empty", "---\nbump: patch\n---\n\n", Fragment{}, "the fragment a.md has no description"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := Parse("a.md", []byte(tc.data))
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, f)
+		})
+	}
+}
+
+func TestRead(t *testing.T) {
+	dir, err := ioutil.TempDir("", "release-fragment")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	fragments, err := Read(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, fragments)
+
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, Dir), 0755))
+	files := map[string]string{
+		"README.md":    "# Change fragments\n",
+		".draft.md":    "draft",
+		"notes.txt":    "notes",
+		"2-fix.md":     "---\nbump: patch\n---\nFix tags.\n",
+		"1-retract.md": "---\nbump: minor\n---\nRetract releases.\n",
+	}
+	for name, data := range files {
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, Dir, name), []byte(data), 0644))
+	}
+
+	fragments, err = Read(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []Fragment{
+		{Path: ".changes/1-retract.md", Bump: Minor, Body: "Retract releases."},
+		{Path: ".changes/2-fix.md", Bump: Patch, Body: "Fix tags."},
+	}, fragments)
+}
+
+func TestBumpAndNotes(t *testing.T) {
+	fragments := []Fragment{
+		{Bump: Patch, Body: "Fix tags."},
+		{Bump: Minor, Body: "Retract releases.\n\n```\nrelease retract v1.2.3\n```"},
+		{Bump: Patch, Body: "Fix branches."},
+	}
+
+	assert.Equal(t, "", Bump(nil))
+	assert.Equal(t, Patch, Bump(fragments[:1]))
+	assert.Equal(t, Minor, Bump(fragments))
+	assert.Equal(t, "### Minor Changes\n\n- Retract releases.\n\n  ```\n  release retract v1.2.3\n  ```\n\n"+
+		"### Patch Changes\n\n- Fix tags.\n- Fix branches.\n", Notes(fragments))
+}
+
+func TestNew(t *testing.T) {
+	now := time.Date(2020, 5, 1, 12, 30, 45, 0, time.UTC)
+
+	path, data, err := New(Minor, "  Retract releases with `release retract`!\nDetails\n", now)
+	assert.NoError(t, err)
+	assert.Equal(t, ".changes/20200501-123045-retract-releases-with-release-retract.md", path)
+	assert.Equal(t, "---\nbump: minor\n---\nRetract releases with `release retract`!\nDetails\n", string(data))
+
+	f, err := Parse(path, data)
+	assert.NoError(t, err)
+	assert.Equal(t, Minor, f.Bump)
+
+	_, _, err = New("feature", "Fix tags.", now)
+	assert.EqualError(t, err, `unknown bump "feature", expected one of [major minor patch]`)
+	_, _, err = New(Patch, " \n", now)
+	assert.EqualError(t, err, "the description is empty")
+}
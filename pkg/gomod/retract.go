@@ -0,0 +1,89 @@
+// Package gomod edits the go.mod file of the released module.
+package gomod
+
+import (
+	"fmt"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/semver"
+)
+
+// FileName is the name of the module file in the module root directory.
+const FileName = "go.mod"
+
+// Retraction is a retracted version interval of the module.
+type Retraction struct {
+	// Low is the lowest retracted version.
+	Low string
+	// High is the highest retracted version. It's equal to Low for a single version.
+	High string
+	// Rationale is the reason of the retraction.
+	Rationale string
+}
+
+// Covers reports whether the given version is part of the retracted interval.
+func (r Retraction) Covers(version string) bool {
+	return semver.Compare(r.Low, version) <= 0 && semver.Compare(version, r.High) <= 0
+}
+
+// String returns the retraction in the notation of the go.mod file.
+func (r Retraction) String() string {
+	if r.Low == r.High {
+		return r.Low
+	}
+	return fmt.Sprintf("[%v, %v]", r.Low, r.High)
+}
+
+// Retractions returns all retract directives of the go.mod data.
+func Retractions(data []byte) ([]Retraction, error) {
+	f, err := modfile.ParseLax(FileName, data, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var retractions = make([]Retraction, 0, len(f.Retract))
+	for _, r := range f.Retract {
+		retractions = append(retractions, Retraction{
+			Low:       r.Low,
+			High:      r.High,
+			Rationale: r.Rationale,
+		})
+	}
+
+	return retractions, nil
+}
+
+// Retract adds a retract directive of the version interval from low to high to the go.mod data and returns the
+// formatted result. The versions must be valid semantic versions and not already retracted.
+func Retract(data []byte, r Retraction) ([]byte, error) {
+	if r.High == "" {
+		r.High = r.Low
+	}
+	for _, v := range []string{r.Low, r.High} {
+		if !semver.IsValid(v) {
+			return nil, fmt.Errorf("invalid semantic version %q", v)
+		}
+	}
+	if semver.Compare(r.Low, r.High) > 0 {
+		return nil, fmt.Errorf("the lower version %v is greater than the higher version %v", r.Low, r.High)
+	}
+
+	f, err := modfile.Parse(FileName, data, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, existing := range f.Retract {
+		e := Retraction{Low: existing.Low, High: existing.High}
+		if e.Covers(r.Low) && e.Covers(r.High) {
+			return nil, fmt.Errorf("the version %v is already retracted by %v", r, e)
+		}
+	}
+
+	if err := f.AddRetract(modfile.VersionInterval{Low: r.Low, High: r.High}, r.Rationale); err != nil {
+		return nil, err
+	}
+	f.Cleanup()
+
+	return modfile.Format(f.Syntax), nil
+}
@@ -0,0 +1,65 @@
+package gomod
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const goMod = `module example.com/m
+
+go 1.16
+
+retract v1.0.1 // broken build
+`
+
+func TestRetract(t *testing.T) {
+	tt := []struct {
+		name       string
+		retraction Retraction
+		expected   string
+		fails      bool
+	}{
+		{
+			name:       "single version",
+			retraction: Retraction{Low: "v1.4.2", Rationale: "panics on start"},
+			expected: "module example.com/m\n\ngo 1.16\n\n" +
+				"retract (\n\tv1.0.1 // broken build\n\t// panics on start\n\tv1.4.2\n)\n",
+		},
+		{
+			name:       "version range",
+			retraction: Retraction{Low: "v1.4.0", High: "v1.4.2"},
+			expected: "module example.com/m\n\ngo 1.16\n\n" +
+				"retract (\n\tv1.0.1 // broken build\n\t[v1.4.0, v1.4.2]\n)\n",
+		},
+		{name: "already retracted", retraction: Retraction{Low: "v1.0.1"}, fails: true},
+		{name: "invalid version", retraction: Retraction{Low: "1.4.2"}, fails: true},
+		{name: "inverted range", retraction: Retraction{Low: "v1.4.2", High: "v1.4.0"}, fails: true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := Retract([]byte(goMod), tc.retraction)
+			if tc.fails {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, string(data))
+		})
+	}
+}
+
+func TestRetractions(t *testing.T) {
+	retractions, err := Retractions([]byte(goMod + "retract [v1.2.0, v1.3.0]\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, []Retraction{
+		{Low: "v1.0.1", High: "v1.0.1", Rationale: "broken build"},
+		{Low: "v1.2.0", High: "v1.3.0"},
+	}, retractions)
+
+	assert.True(t, retractions[1].Covers("v1.2.5"))
+	assert.True(t, retractions[1].Covers("v1.3.0"))
+	assert.False(t, retractions[1].Covers("v1.3.1"))
+	assert.Equal(t, "[v1.2.0, v1.3.0]", retractions[1].String())
+}
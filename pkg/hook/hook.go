@@ -0,0 +1,112 @@
+// Package hook runs the configured commands of the project around the steps of a release, e.g. to regenerate docs,
+// build artifacts or notify a chat.
+package hook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+
+	"github.com/exaring/release-cli/pkg/output"
+)
+
+// Runner runs the commands of the hooks.
+type Runner struct {
+	// Commands maps the hook names to their commands.
+	Commands map[string][]string
+	// Dir is the working directory of the commands.
+	Dir string
+	// Output receives the output of the commands. Every line is prefixed with the hook name.
+	Output io.Writer
+}
+
+// Run runs the commands of the hook one after another with the shell and stops at the first failing command. The
+// commands get the details of the release as RELEASE_* environment variables and as JSON file, whose path is passed
+// in RELEASE_JSON, besides the given additional environment variables.
+func (r *Runner) Run(ctx context.Context, name string, release output.Release, env ...string) error {
+	commands := r.Commands[name]
+	if len(commands) == 0 {
+		return nil
+	}
+
+	file, err := writeJSON(release)
+	if err != nil {
+		return fmt.Errorf("hook %v: %w", name, err)
+	}
+	defer os.Remove(file)
+
+	env = append(append(append(os.Environ(), release.Environ()...), env...),
+		"RELEASE_HOOK="+name, "RELEASE_JSON="+file)
+
+	for _, command := range commands {
+		out := &prefixWriter{w: r.Output, prefix: "[" + name + "] "}
+
+		cmd := exec.CommandContext(ctx, "sh", "-c", command)
+		cmd.Dir = r.Dir
+		cmd.Env = env
+		cmd.Stdout = out
+		cmd.Stderr = out
+
+		err := cmd.Run()
+		if flushErr := out.Flush(); err == nil {
+			err = flushErr
+		}
+		if err != nil {
+			return fmt.Errorf("hook %v: %q failed: %w", name, command, err)
+		}
+	}
+
+	return nil
+}
+
+// writeJSON writes the release details into a temporary JSON file and returns its path.
+func writeJSON(release output.Release) (string, error) {
+	f, err := ioutil.TempFile("", "release-hook-*.json")
+	if err != nil {
+		return "", fmt.Errorf("failed to create the release file: %w", err)
+	}
+	defer f.Close()
+
+	if err := json.NewEncoder(f).Encode(release); err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("failed to write the release file: %w", err)
+	}
+	return f.Name(), f.Close()
+}
+
+// prefixWriter writes every complete line with the prefix.
+type prefixWriter struct {
+	w      io.Writer
+	prefix string
+	buf    []byte
+}
+
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	p.buf = append(p.buf, b...)
+	for {
+		i := bytes.IndexByte(p.buf, '\n')
+		if i < 0 {
+			break
+		}
+		if _, err := fmt.Fprintf(p.w, "%v%s", p.prefix, p.buf[:i+1]); err != nil {
+			return 0, err
+		}
+		p.buf = p.buf[i+1:]
+	}
+	return len(b), nil
+}
+
+// Flush writes the last incomplete line.
+func (p *prefixWriter) Flush() error {
+	if len(p.buf) == 0 {
+		return nil
+	}
+	_, err := fmt.Fprintf(p.w, "%v%s\n", p.prefix, p.buf)
+	p.buf = nil
+	return err
+}
@@ -0,0 +1,48 @@
+package hook
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/exaring/release-cli/pkg/output"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunner_Run(t *testing.T) {
+	release := output.Release{PreviousVersion: "v1.2.3", Version: "v1.3.0", Tag: "v1.3.0", Bump: "minor"}
+
+	tt := []struct {
+		name     string
+		commands []string
+		env      []string
+		expected string
+		fails    bool
+	}{
+		{"none", nil, nil, "", false},
+		{"env", []string{"echo $RELEASE_HOOK $RELEASE_TAG $RELEASE_PREVIOUS_VERSION"}, nil,
+			"[env] env v1.3.0 v1.2.3\n", false},
+		{"json", []string{"cat $RELEASE_JSON"}, nil,
+			`[json] {"previous_version":"v1.2.3","version":"v1.3.0","tag":"v1.3.0","commit":"","remote":"",` +
+				`"bump":"minor","dry_run":false}` + "\n", false},
+		{"extra", []string{"echo $RELEASE_ERROR"}, []string{"RELEASE_ERROR=push rejected"},
+			"[extra] push rejected\n", false},
+		{"lines", []string{"printf 'a\\nb'", "echo c >&2"}, nil, "[lines] a\n[lines] b\n[lines] c\n", false},
+		{"fails", []string{"echo a", "exit 3", "echo b"}, nil, "[fails] a\n", true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			r := &Runner{Commands: map[string][]string{tc.name: tc.commands}, Output: &buf}
+
+			err := r.Run(context.Background(), tc.name, release, tc.env...)
+			if tc.fails {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expected, buf.String())
+		})
+	}
+}
@@ -0,0 +1,115 @@
+// Package lock serializes concurrent releases with an advisory lock file on the local machine and an optional lock
+// reference on the remote, which is shared by all machines.
+package lock
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+)
+
+// RemoteRef is the reference of the remote lock. It's nested below refs/release-lock, because git servers reject
+// one-level reference names like refs/release-lock.
+const RemoteRef = "refs/release-lock/current"
+
+// File is an acquired lock file.
+type File struct {
+	path string
+}
+
+// Acquire creates the lock file, which records the owner of the lock. It returns an error if the file already exists,
+// because another release holds the lock.
+func Acquire(path, owner string) (*File, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if os.IsExist(err) {
+		holder, _ := ioutil.ReadFile(path)
+		name := strings.TrimSpace(string(holder))
+		if name == "" {
+			name = "an unknown release"
+		}
+		return nil, fmt.Errorf("the release lock %v is held by %v, remove the file if the release isn't running",
+			path, name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the release lock: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := fmt.Fprintln(f, owner); err != nil {
+		os.Remove(path)
+		return nil, fmt.Errorf("failed to write the release lock: %w", err)
+	}
+	return &File{path: path}, f.Close()
+}
+
+// Release removes the lock file.
+func (f *File) Release() error {
+	return os.Remove(f.path)
+}
+
+// Repository pushes references to the remote.
+type Repository interface {
+	// RemoteReferences lists the references of the remote with their commit hashes.
+	RemoteReferences(ctx context.Context) (map[string]string, error)
+	// CreateOrphanCommit creates a commit without parents and files and returns its hash.
+	CreateOrphanCommit(message string) (string, error)
+	// SetReference creates or moves the reference with the full name to the commit hash.
+	SetReference(name, hash string) error
+	// RemoveReference deletes the reference with the full name.
+	RemoveReference(name string) error
+	// Push pushes the given ref specs of the local repo to the remote.
+	Push(ctx context.Context, refSpecs ...string) error
+}
+
+// Remote is an acquired lock reference on the remote.
+type Remote struct {
+	repo Repository
+	hash string
+}
+
+// AcquireRemote pushes the lock reference to the remote, if it doesn't exist. The reference points to a new commit
+// without parents, so that the push is rejected as non-fast-forward update if another release created the reference
+// meanwhile, and the remote only accepts the update if the reference is unchanged since the push started.
+func AcquireRemote(ctx context.Context, repo Repository, owner string) (*Remote, error) {
+	refs, err := repo.RemoteReferences(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the remote references: %w", err)
+	}
+	if hash, ok := refs[RemoteRef]; ok {
+		return nil, fmt.Errorf("the remote release lock %v is held by the commit %v", RemoteRef, hash)
+	}
+
+	hash, err := repo.CreateOrphanCommit(fmt.Sprintf("Release lock of %v at %v", owner, time.Now().Format(time.RFC3339)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the release lock commit: %w", err)
+	}
+	if err := repo.SetReference(RemoteRef, hash); err != nil {
+		return nil, err
+	}
+	defer repo.RemoveReference(RemoteRef)
+
+	if err := repo.Push(ctx, fmt.Sprintf("%[1]v:%[1]v", RemoteRef)); err != nil {
+		return nil, fmt.Errorf("failed to acquire the remote release lock %v: %w", RemoteRef, err)
+	}
+
+	return &Remote{repo: repo, hash: hash}, nil
+}
+
+// Release deletes the lock reference on the remote, if it's still held by the lock.
+func (r *Remote) Release(ctx context.Context) error {
+	refs, err := r.repo.RemoteReferences(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list the remote references: %w", err)
+	}
+	if refs[RemoteRef] != r.hash {
+		return fmt.Errorf("the remote release lock %v was taken over by the commit %v", RemoteRef, refs[RemoteRef])
+	}
+
+	if err := r.repo.Push(ctx, ":"+RemoteRef); err != nil {
+		return fmt.Errorf("failed to release the remote release lock %v: %w", RemoteRef, err)
+	}
+	return nil
+}
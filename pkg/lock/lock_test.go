@@ -0,0 +1,77 @@
+package lock
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/exaring/release-cli/pkg/repository"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAcquire(t *testing.T) {
+	dir, err := ioutil.TempDir("", "release-lock")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "release.lock")
+
+	f, err := Acquire(path, "job 1")
+	assert.NoError(t, err)
+
+	_, err = Acquire(path, "job 2")
+	assert.EqualError(t, err, "the release lock "+path+" is held by job 1, remove the file if the release isn't running")
+
+	assert.NoError(t, f.Release())
+	f, err = Acquire(path, "job 2")
+	assert.NoError(t, err)
+	assert.NoError(t, f.Release())
+}
+
+// cloneRepository creates a bare remote repository and returns two repositories with the remote as origin.
+func cloneRepository(t *testing.T, dir string) (*repository.Git, *repository.Git) {
+	remote := filepath.Join(dir, "remote.git")
+	_, err := git.PlainInit(remote, true)
+	assert.NoError(t, err)
+
+	var clones []*repository.Git
+	for _, name := range []string{"a", "b"} {
+		path := filepath.Join(dir, name)
+		r, err := git.PlainInit(path, false)
+		assert.NoError(t, err)
+		_, err = r.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{remote}})
+		assert.NoError(t, err)
+
+		clone, err := repository.New(path)
+		assert.NoError(t, err)
+		clones = append(clones, clone)
+	}
+	return clones[0], clones[1]
+}
+
+func TestAcquireRemote(t *testing.T) {
+	dir, err := ioutil.TempDir("", "release-lock")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	a, b := cloneRepository(t, dir)
+	ctx := context.Background()
+
+	lockA, err := AcquireRemote(ctx, a, "job a")
+	assert.NoError(t, err)
+
+	_, err = AcquireRemote(ctx, b, "job b")
+	assert.Error(t, err)
+
+	assert.NoError(t, lockA.Release(ctx))
+	refs, err := b.RemoteReferences(context.Background())
+	assert.NoError(t, err)
+	assert.NotContains(t, refs, RemoteRef)
+
+	lockB, err := AcquireRemote(ctx, b, "job b")
+	assert.NoError(t, err)
+	assert.Error(t, lockA.Release(ctx))
+	assert.NoError(t, lockB.Release(ctx))
+}
@@ -0,0 +1,169 @@
+// Package modzip validates the Go module zip of a release, which the module proxy serves to "go get".
+package modzip
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"time"
+
+	"github.com/exaring/release-cli/pkg/repository"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/sumdb/dirhash"
+	modzip "golang.org/x/mod/zip"
+)
+
+// Violation is a file which isn't part of the module zip.
+type Violation struct {
+	// Path is the slash separated path of the file. It's empty if the violation concerns the whole module.
+	Path string
+	// Reason describes why the file is omitted or invalid.
+	Reason string
+}
+
+// String returns the violation as string.
+func (v Violation) String() string {
+	if v.Path == "" {
+		return v.Reason
+	}
+	return fmt.Sprintf("%v: %v", v.Path, v.Reason)
+}
+
+// Result is the result of the module zip validation.
+type Result struct {
+	// Module is the module path and version, e.g. example.com/m@v1.2.3.
+	Module string
+	// Files lists all files of the module zip.
+	Files []string
+	// Omitted lists all files which are silently left out of the module zip, e.g. vendored files.
+	Omitted []Violation
+	// Invalid lists all violations which prevent the creation of the module zip.
+	Invalid []Violation
+	// Size is the size of the module zip in bytes.
+	Size int64
+	// Hash is the h1: hash of the module zip, which is recorded in the go.sum files of the module users.
+	Hash string
+}
+
+// Err returns an error if the module zip can't be created.
+func (r Result) Err() error {
+	if len(r.Invalid) > 0 {
+		return fmt.Errorf("the module zip of %v has %d violations", r.Module, len(r.Invalid))
+	}
+	return nil
+}
+
+// Check builds the module zip of the given version in memory and validates it against the rules of the module proxy.
+// The module path is read from the go.mod file of the files.
+func Check(files []repository.File, version string) (Result, error) {
+	var goMod []byte
+	var zipFiles = make([]modzip.File, 0, len(files))
+	for _, f := range files {
+		if f.Path == "go.mod" {
+			goMod = f.Data
+		}
+		zipFiles = append(zipFiles, file{f})
+	}
+
+	if goMod == nil {
+		return Result{}, fmt.Errorf("the files don't contain a go.mod file")
+	}
+	modulePath := modfile.ModulePath(goMod)
+	if modulePath == "" {
+		return Result{}, fmt.Errorf("the go.mod file doesn't declare a module path")
+	}
+
+	m := module.Version{Path: modulePath, Version: version}
+	result := Result{Module: m.String()}
+
+	if err := module.Check(m.Path, m.Version); err != nil {
+		result.Invalid = append(result.Invalid, Violation{Reason: err.Error()})
+	}
+
+	checked, _ := modzip.CheckFiles(zipFiles)
+	result.Files = checked.Valid
+	for _, fe := range checked.Omitted {
+		result.Omitted = append(result.Omitted, Violation{Path: fe.Path, Reason: fe.Err.Error()})
+	}
+	for _, fe := range checked.Invalid {
+		result.Invalid = append(result.Invalid, Violation{Path: fe.Path, Reason: fe.Err.Error()})
+	}
+	if checked.SizeError != nil {
+		result.Invalid = append(result.Invalid, Violation{Reason: checked.SizeError.Error()})
+	}
+
+	if len(result.Invalid) > 0 {
+		return result, nil
+	}
+
+	var buf bytes.Buffer
+	if err := modzip.Create(&buf, m, zipFiles); err != nil {
+		return result, err
+	}
+	result.Size = int64(buf.Len())
+
+	hash, err := hashZip(buf.Bytes())
+	if err != nil {
+		return result, err
+	}
+	result.Hash = hash
+
+	return result, nil
+}
+
+// hashZip computes the h1: hash of the module zip like dirhash.HashZip of the go command.
+func hashZip(data []byte) (string, error) {
+	z, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", err
+	}
+
+	var names = make([]string, 0, len(z.File))
+	var entries = make(map[string]*zip.File, len(z.File))
+	for _, f := range z.File {
+		names = append(names, f.Name)
+		entries[f.Name] = f
+	}
+
+	return dirhash.Hash1(names, func(name string) (io.ReadCloser, error) {
+		f, ok := entries[name]
+		if !ok {
+			return nil, fmt.Errorf("file %q not found in zip", name)
+		}
+		return f.Open()
+	})
+}
+
+// file adapts a repository file to the file abstraction of the module zip.
+type file struct {
+	repository.File
+}
+
+func (f file) Path() string {
+	return f.File.Path
+}
+
+func (f file) Lstat() (os.FileInfo, error) {
+	return fileInfo{f.File}, nil
+}
+
+func (f file) Open() (io.ReadCloser, error) {
+	return ioutil.NopCloser(bytes.NewReader(f.Data)), nil
+}
+
+// fileInfo is the os.FileInfo of a repository file.
+type fileInfo struct {
+	repository.File
+}
+
+func (fi fileInfo) Name() string       { return path.Base(fi.File.Path) }
+func (fi fileInfo) Size() int64        { return int64(len(fi.Data)) }
+func (fi fileInfo) Mode() os.FileMode  { return fi.File.Mode }
+func (fi fileInfo) ModTime() time.Time { return time.Time{} }
+func (fi fileInfo) IsDir() bool        { return false }
+func (fi fileInfo) Sys() interface{}   { return nil }
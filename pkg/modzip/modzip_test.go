@@ -0,0 +1,88 @@
+package modzip
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/exaring/release-cli/pkg/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheck(t *testing.T) {
+	tt := []struct {
+		name     string
+		version  string
+		files    []repository.File
+		valid    []string
+		omitted  []string
+		invalid  []string
+		hashless bool
+	}{
+		{
+			name:    "valid",
+			version: "v1.2.3",
+			files: []repository.File{
+				{Path: "go.mod", Mode: 0644, Data: []byte("module example.com/m\n")},
+				{Path: "m.go", Mode: 0644, Data: []byte("package m\n")},
+				{Path: "vendor/example.com/dep/dep.go", Mode: 0644, Data: []byte("package dep\n")},
+				{Path: "sub/go.mod", Mode: 0644, Data: []byte("module example.com/m/sub\n")},
+			},
+			valid:   []string{"go.mod", "m.go"},
+			omitted: []string{"vendor/example.com/dep/dep.go", "sub/go.mod"},
+		},
+		{
+			name:    "case-insensitive collision",
+			version: "v1.2.3",
+			files: []repository.File{
+				{Path: "go.mod", Mode: 0644, Data: []byte("module example.com/m\n")},
+				{Path: "README.md", Mode: 0644},
+				{Path: "readme.md", Mode: 0644},
+				{Path: "a:b.go", Mode: 0644},
+			},
+			valid:    []string{"go.mod", "README.md"},
+			invalid:  []string{"readme.md", "a:b.go"},
+			hashless: true,
+		},
+		{
+			name:    "missing major version suffix",
+			version: "v2.0.0",
+			files: []repository.File{
+				{Path: "go.mod", Mode: 0644, Data: []byte("module example.com/m\n")},
+			},
+			valid:    []string{"go.mod"},
+			invalid:  []string{""},
+			hashless: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := Check(tc.files, tc.version)
+			assert.NoError(t, err)
+
+			var omitted, invalid []string
+			for _, v := range result.Omitted {
+				omitted = append(omitted, v.Path)
+			}
+			for _, v := range result.Invalid {
+				invalid = append(invalid, v.Path)
+			}
+
+			assert.ElementsMatch(t, tc.valid, result.Files)
+			assert.ElementsMatch(t, tc.omitted, omitted)
+			assert.ElementsMatch(t, tc.invalid, invalid)
+			if tc.hashless {
+				assert.Error(t, result.Err())
+				assert.Empty(t, result.Hash)
+				return
+			}
+			assert.NoError(t, result.Err())
+			assert.True(t, strings.HasPrefix(result.Hash, "h1:"))
+		})
+	}
+}
+
+func TestCheck_MissingModFile(t *testing.T) {
+	_, err := Check([]repository.File{{Path: "m.go", Mode: 0644}}, "v1.0.0")
+	assert.Error(t, err)
+}
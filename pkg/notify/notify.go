@@ -0,0 +1,208 @@
+// Package notify posts a templated JSON payload of a published release to webhooks, e.g. to announce the release in a
+// Slack or Teams channel.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/exaring/release-cli/pkg/output"
+)
+
+const (
+	// Slack is the preset of Slack incoming webhooks and compatible chats like Mattermost and Rocket.Chat.
+	Slack = "slack"
+	// Teams is the preset of Microsoft Teams incoming webhooks.
+	Teams = "teams"
+)
+
+// Presets lists all supported presets.
+var Presets = []string{Slack, Teams}
+
+// presets are the payload templates of the presets. The default payload is the JSON of the release.
+var presets = map[string]string{
+	Slack: `{"text": {{json (printf "Released %v of %v" .Tag .Remote)}}}`,
+	Teams: `{"@type": "MessageCard", "@context": "https://schema.org/extensions", ` +
+		`"summary": {{json (printf "Released %v" .Tag)}}, "title": {{json (printf "Released %v" .Tag)}}, ` +
+		`"text": {{json (printf "%v of %v at commit %v" .Tag .Remote .Commit)}}}`,
+	"": `{{json .}}`,
+}
+
+const (
+	// DefaultTimeout limits each attempt to send the payload.
+	DefaultTimeout = 10 * time.Second
+	// DefaultBackoff is the delay before the first retry, which doubles with every retry.
+	DefaultBackoff = time.Second
+)
+
+// Options configure a webhook.
+type Options struct {
+	// URL is the URL of the webhook. It may contain secrets, so it's never logged.
+	URL string
+	// Preset is the payload format of a chat: slack or teams. The default payload is the JSON of the release.
+	Preset string
+	// Template is the text/template of the JSON payload, which overrides the preset. The release is the data of the
+	// template and the json function encodes a value as JSON.
+	Template string
+	// Headers are added to the request.
+	Headers map[string]string
+	// Timeout limits each attempt. It defaults to DefaultTimeout.
+	Timeout time.Duration
+	// Retries is the number of times a failed request is repeated.
+	Retries int
+	// Backoff is the delay before the first retry. It defaults to DefaultBackoff.
+	Backoff time.Duration
+	// Client sends the requests. It defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+// Webhook posts the payload of a release to a URL.
+type Webhook struct {
+	opts     Options
+	template *template.Template
+}
+
+// New returns the webhook of the options. It returns an error if the preset or the template is invalid.
+func New(opts Options) (*Webhook, error) {
+	target, err := url.Parse(opts.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return nil, fmt.Errorf("invalid webhook URL, expected an http or https URL")
+	}
+
+	tmpl, err := parse(opts.Preset, opts.Template)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = DefaultBackoff
+	}
+	if opts.Retries < 0 {
+		return nil, fmt.Errorf("negative number of retries %d", opts.Retries)
+	}
+	if opts.Client == nil {
+		opts.Client = http.DefaultClient
+	}
+	return &Webhook{opts: opts, template: tmpl}, nil
+}
+
+// Check returns an error if the preset is unknown or the template is invalid.
+func Check(preset, text string) error {
+	_, err := parse(preset, text)
+	return err
+}
+
+// parse returns the payload template of the preset or the template text, which overrides the preset.
+func parse(preset, text string) (*template.Template, error) {
+	payload, ok := presets[preset]
+	if !ok {
+		return nil, fmt.Errorf("unknown preset %q, expected one of %v", preset, Presets)
+	}
+	if text != "" {
+		payload = text
+	}
+	tmpl, err := template.New("payload").Option("missingkey=error").Funcs(template.FuncMap{"json": encode}).Parse(payload)
+	if err != nil {
+		return nil, fmt.Errorf("invalid payload template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// Host returns the host of the webhook URL, which identifies the webhook without revealing secrets.
+func (w *Webhook) Host() string {
+	target, _ := url.Parse(w.opts.URL)
+	return target.Host
+}
+
+// Payload renders the payload of the release. The credentials of the remote URL are removed, so that they aren't
+// posted to a chat. It returns an error if the payload isn't valid JSON.
+func (w *Webhook) Payload(release output.Release) ([]byte, error) {
+	release.Remote = output.RedactURL(release.Remote)
+
+	var buf bytes.Buffer
+	if err := w.template.Execute(&buf, release); err != nil {
+		return nil, fmt.Errorf("failed to render the payload: %w", err)
+	}
+	if !json.Valid(buf.Bytes()) {
+		return nil, fmt.Errorf("the payload isn't valid JSON: %v", buf.String())
+	}
+	return buf.Bytes(), nil
+}
+
+// Send posts the payload of the release. Failed requests are repeated with exponential backoff, unless the webhook
+// rejects the payload with a client error or the context is done.
+func (w *Webhook) Send(ctx context.Context, release output.Release) error {
+	payload, err := w.Payload(release)
+	if err != nil {
+		return err
+	}
+
+	delay := w.opts.Backoff
+	for attempt := 0; ; attempt++ {
+		retry, err := w.post(ctx, payload)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= w.opts.Retries || ctx.Err() != nil {
+			return fmt.Errorf("failed to notify %v after %d attempts: %w", w.Host(), attempt+1, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("failed to notify %v: %w", w.Host(), ctx.Err())
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// post sends the payload once and reports whether a failed request may be repeated.
+func (w *Webhook) post(ctx context.Context, payload []byte) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, w.opts.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequest(http.MethodPost, w.opts.URL, bytes.NewReader(payload))
+	if err != nil {
+		return false, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range w.opts.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := w.opts.Client.Do(req)
+	if err != nil {
+		// the error of the client contains the URL
+		if urlErr, ok := err.(*url.Error); ok {
+			err = urlErr.Err
+		}
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		message, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		err := fmt.Errorf("%v %v", resp.Status, strings.TrimSpace(string(message)))
+		return resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests, err
+	}
+	return false, nil
+}
+
+// encode returns the JSON of the value for the templates.
+func encode(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	return string(data), err
+}
@@ -0,0 +1,105 @@
+package release
+
+import (
+	"fmt"
+
+	"github.com/exaring/release-cli/pkg/apicheck"
+	"github.com/exaring/release-cli/pkg/modzip"
+	"github.com/exaring/release-cli/pkg/version"
+)
+
+// CheckAPI compares the exported Go API of the previous version tag with the current HEAD. It returns a PolicyError if
+// the requested release part is too small for the incompatible changes. Without a requested part the required part
+// is only suggested.
+func CheckAPI(logger Logger, vc Repository, format version.Format, previous version.Version, major, minor, patch bool) error {
+	oldFiles, err := vc.Files(format.Tag(previous))
+	if err != nil {
+		return fmt.Errorf("failed to read the files of the previous version %v: %w", previous, err)
+	}
+	newFiles, err := vc.Files("HEAD")
+	if err != nil {
+		return fmt.Errorf("failed to read the files of the current commit: %w", err)
+	}
+
+	report, err := apicheck.Compare(oldFiles, newFiles)
+	if err != nil {
+		return fmt.Errorf("failed to compare the API: %w", err)
+	}
+
+	for _, c := range report.Incompatible() {
+		logger.WithFields(Fields{
+			"Package": c.Package,
+		}).Warnf("Incompatible API change: %v", c.Message)
+	}
+	for _, c := range report.Compatible() {
+		logger.WithFields(Fields{
+			"Package": c.Package,
+		}).Infof("Compatible API change: %v", c.Message)
+	}
+
+	required := report.RequiredPart(previous)
+
+	var requested int
+	switch {
+	case major:
+		requested = version.Major
+	case minor:
+		requested = version.Minor
+	case patch:
+		requested = version.Patch
+	default:
+		logger.WithFields(Fields{
+			"Part": version.PartName(required),
+		}).Infof("Suggest the release part for the API changes")
+		return nil
+	}
+
+	if requested <= required {
+		return nil
+	}
+	if incompatible := report.Incompatible(); len(incompatible) > 0 {
+		return &PolicyError{Err: fmt.Errorf("the %v release contains %d incompatible API changes, at least a %v "+
+			"release is required", version.PartName(requested), len(incompatible), version.PartName(required))}
+	}
+
+	logger.WithFields(Fields{
+		"Part": version.PartName(required),
+	}).Warnf("The API contains new features, consider a larger release part")
+
+	return nil
+}
+
+// CheckModuleZip builds the module zip of the new version from the files of the revision and returns an error if the
+// module proxy would reject it. It returns the h1: hash of a valid module zip, which is only the hash of the release if
+// the revision is the tagged commit.
+func CheckModuleZip(logger Logger, vc Repository, format version.Format, v version.Version, revision string) (string, error) {
+	files, err := vc.Files(revision)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the files of %v: %w", revision, err)
+	}
+
+	result, err := modzip.Check(files, format.Version(v))
+	if err != nil {
+		return "", fmt.Errorf("failed to create the module zip: %w", err)
+	}
+
+	for _, o := range result.Omitted {
+		logger.Debugf("Omit file from the module zip: %v", o)
+	}
+	for _, i := range result.Invalid {
+		logger.Errorf("Invalid module zip: %v", i)
+	}
+	if err := result.Err(); err != nil {
+		return "", err
+	}
+
+	logger.WithFields(Fields{
+		"Revision": revision,
+		"Module":   result.Module,
+		"Files":    len(result.Files),
+		"Size":     result.Size,
+		"Hash":     result.Hash,
+	}).Infof("Validate the module zip")
+
+	return result.Hash, nil
+}
@@ -0,0 +1,78 @@
+package release
+
+import (
+	"fmt"
+
+	"github.com/exaring/release-cli/pkg/version"
+)
+
+// NoVersionError is returned if the repository, the branch or the release line has no version yet.
+type NoVersionError struct {
+	// Branch is the branch whose tags are tracked. It's empty for all tags of the repository.
+	Branch string
+	// Line is the release line. It's nil if the versions aren't limited to a line.
+	Line *version.Line
+}
+
+func (e *NoVersionError) Error() string {
+	switch {
+	case e.Line != nil:
+		return fmt.Sprintf("the release line %v has no versions", e.Line)
+	case e.Branch != "":
+		return fmt.Sprintf("the version list of the branch %v is empty", e.Branch)
+	default:
+		return "the version list is empty"
+	}
+}
+
+// UnsafeError is returned if the repository is in an unsafe state, e.g. has uncommitted changes or is behind the
+// remote, and the release isn't forced.
+type UnsafeError struct {
+	Err error
+}
+
+func (e *UnsafeError) Error() string {
+	return fmt.Sprintf("repository is in unsafe state and force is not set: %v", e.Err)
+}
+
+func (e *UnsafeError) Unwrap() error {
+	return e.Err
+}
+
+// PolicyError is returned if the branch policy or the release line refuses the release.
+type PolicyError struct {
+	Err error
+}
+
+func (e *PolicyError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PolicyError) Unwrap() error {
+	return e.Err
+}
+
+// TagExistsError is returned if the tag of the new version already exists on the remote, e.g. because a concurrent
+// release was faster.
+type TagExistsError struct {
+	Tag string
+}
+
+func (e *TagExistsError) Error() string {
+	return fmt.Sprintf("the tag %v already exists on the remote", e.Tag)
+}
+
+// PublishError is returned if setting or pushing the references of the release failed. The completed steps are rolled
+// back, unless the rollback failed as well and the release has to be recovered from the journal.
+type PublishError struct {
+	Tag string
+	Err error
+}
+
+func (e *PublishError) Error() string {
+	return fmt.Sprintf("failed to publish %v: %v", e.Tag, e.Err)
+}
+
+func (e *PublishError) Unwrap() error {
+	return e.Err
+}
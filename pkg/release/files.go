@@ -0,0 +1,226 @@
+package release
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/exaring/release-cli/pkg/changelog"
+	"github.com/exaring/release-cli/pkg/fragment"
+	"github.com/exaring/release-cli/pkg/transaction"
+	"github.com/exaring/release-cli/pkg/version"
+	"github.com/exaring/release-cli/pkg/versionfile"
+)
+
+// FileVersion returns the version as written into version files, which is the tag without prefix.
+func FileVersion(format version.Format, v version.Version) string {
+	return version.Format{PreLabel: format.PreLabel}.Tag(v)
+}
+
+// fragmentBump returns the flags of the version part, which is increased by the largest change fragment. The options
+// take precedence over the fragments and the fragments are ignored while a release candidate of the latest version
+// is open, because its version is fixed already.
+func (r *Releaser) fragmentBump(latest version.Version, fragments []fragment.Fragment) (major, minor, patch bool) {
+	major, minor, patch = r.opts.Major, r.opts.Minor, r.opts.Patch
+	if major || minor || patch || latest.IsReleaseCandidate() {
+		return major, minor, patch
+	}
+
+	bump := fragment.Bump(fragments)
+	if bump != "" {
+		r.logger.WithFields(Fields{
+			"Bump":      bump,
+			"Fragments": len(fragments),
+		}).Infof("Increase the version part of the change fragments")
+	}
+	return bump == fragment.Major, bump == fragment.Minor, bump == fragment.Patch
+}
+
+// versionFileChanges replaces the previous version with the next version in the version files. It returns an error if
+// a file doesn't contain the previous version.
+func (r *Releaser) versionFileChanges(plan *Plan) ([]versionfile.Change, error) {
+	var files = make([]versionfile.File, 0, len(r.opts.VersionFiles))
+	for _, f := range r.opts.VersionFiles {
+		file, err := versionfile.New(f.Path, f.Pattern, f.Key)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+
+	changes, err := versionfile.Replace(r.opts.Dir, files, FileVersion(plan.Format, plan.Previous),
+		FileVersion(plan.Format, plan.Next))
+	if err != nil {
+		return nil, fmt.Errorf("failed to update the version files: %w", err)
+	}
+	return changes, nil
+}
+
+// fragmentChanges renders the release notes of the change fragments. Final releases consume the fragments: the notes
+// are added to the changelog file and the fragments are deleted by the release commit. Release candidates keep the
+// fragments for the final release.
+func (r *Releaser) fragmentChanges(plan *Plan) (string, []versionfile.Change, error) {
+	if len(plan.Fragments) == 0 {
+		return "", nil, nil
+	}
+	notes := fragment.Notes(plan.Fragments)
+	r.logger.WithFields(Fields{
+		"Fragments": len(plan.Fragments),
+	}).Debugf("Render the release notes of the change fragments:\n%v", notes)
+	if plan.Next.IsReleaseCandidate() {
+		return notes, nil, nil
+	}
+
+	var changes []versionfile.Change
+	if r.opts.Changelog != "" {
+		data, err := ioutil.ReadFile(filepath.Join(r.opts.Dir, r.opts.Changelog))
+		if err != nil && !os.IsNotExist(err) {
+			return "", nil, fmt.Errorf("failed to read the changelog: %w", err)
+		}
+		changes = append(changes, versionfile.Change{
+			Path: r.opts.Changelog,
+			Data: changelog.Insert(data, FileVersion(plan.Format, plan.Next), time.Now(), notes),
+		})
+	}
+	for _, f := range plan.Fragments {
+		changes = append(changes, versionfile.Change{Path: f.Path, Delete: true})
+	}
+	return notes, changes, nil
+}
+
+// generateVersionFile returns the Go version file of the path and the linker flags, which inject the same details.
+// The linker flags are empty if the import path of the file is unknown.
+func (r *Releaser) generateVersionFile(plan *Plan) (versionfile.Change, string, error) {
+	path, err := filepath.Abs(r.opts.GenVersionFile)
+	if err != nil {
+		return versionfile.Change{}, "", err
+	}
+	root, err := filepath.Abs(r.opts.Dir)
+	if err != nil {
+		return versionfile.Change{}, "", err
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return versionfile.Change{}, "", fmt.Errorf("the version file %v is outside of the repository", path)
+	}
+
+	info := versionfile.GoInfo{
+		Version:         "v" + FileVersion(plan.Format, plan.Next),
+		PreviousVersion: "v" + FileVersion(plan.Format, plan.Previous),
+		Commit:          plan.Commit,
+		Date:            time.Now(),
+	}
+	dir := filepath.Dir(path)
+	data, err := versionfile.GoFile(versionfile.PackageName(dir), info)
+	if err != nil {
+		return versionfile.Change{}, "", fmt.Errorf("failed to generate the version file: %w", err)
+	}
+
+	var ldflags string
+	if importPath, err := versionfile.ImportPath(root, dir); err != nil {
+		r.logger.WithFields(Fields{"error": err}).Debugf("Couldn't detect the import path of the version file")
+	} else {
+		ldflags = versionfile.LDFlags(importPath, info)
+	}
+	return versionfile.Change{Path: filepath.ToSlash(rel), Data: data}, ldflags, nil
+}
+
+// commitFiles writes the changed files and commits them with the message on the current branch. It returns the moved
+// branch or nil if there are no changes and the changes, which restore the previous content of the files. The dry-run
+// mode doesn't write any file.
+func (r *Releaser) commitFiles(message string, plan *Plan, changes []versionfile.Change) (*transaction.Ref, []versionfile.Change, error) {
+	if len(changes) == 0 {
+		return nil, nil, nil
+	}
+
+	var paths = make([]string, 0, len(changes))
+	for _, c := range changes {
+		paths = append(paths, c.Path)
+	}
+	r.logger.WithFields(Fields{
+		"Files": paths,
+	}).Infof("Update the version files")
+
+	if r.opts.Dry {
+		return nil, nil, nil
+	}
+
+	branch, err := r.repo.CurrentBranch()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to detect the current branch: %w", err)
+	}
+
+	backup, err := r.backupFiles(changes)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, c := range changes {
+		if err := os.MkdirAll(filepath.Join(r.opts.Dir, filepath.Dir(c.Path)), 0755); err != nil {
+			return nil, nil, fmt.Errorf("failed to create the directory of %v: %w", c.Path, err)
+		}
+	}
+	if err := versionfile.Write(r.opts.Dir, changes); err != nil {
+		return nil, nil, err
+	}
+
+	hash, err := r.repo.Commit(message, paths...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to commit the version files: %w", err)
+	}
+	r.logger.WithFields(Fields{
+		"Commit": hash,
+	}).Debugf("Commit the version files")
+
+	return &transaction.Ref{Name: "refs/heads/" + branch, Hash: hash, Previous: plan.Commit}, backup, nil
+}
+
+// backupFiles returns the changes, which restore the current content of the changed files. Files, which don't exist
+// yet, are deleted by the restore.
+func (r *Releaser) backupFiles(changes []versionfile.Change) ([]versionfile.Change, error) {
+	var backup = make([]versionfile.Change, 0, len(changes))
+	for _, c := range changes {
+		data, err := ioutil.ReadFile(filepath.Join(r.opts.Dir, c.Path))
+		switch {
+		case os.IsNotExist(err):
+			backup = append(backup, versionfile.Change{Path: c.Path, Delete: true})
+		case err != nil:
+			return nil, fmt.Errorf("failed to read %v: %w", c.Path, err)
+		default:
+			backup = append(backup, versionfile.Change{Path: c.Path, Data: data})
+		}
+	}
+	return backup, nil
+}
+
+// resetCommit moves the branch back to the previous commit and restores the files if the release fails before the
+// transaction runs, which would reset the branch otherwise.
+func (r *Releaser) resetCommit(ref transaction.Ref, backup []versionfile.Change) {
+	if err := r.repo.SetReference(ref.Name, ref.Previous); err != nil {
+		r.logger.WithFields(Fields{"error": err}).Errorf("Couldn't reset the release commit, reset %v with "+
+			"\"git reset --soft %v\"", ref.Name, ref.Previous)
+		return
+	}
+	r.restoreFiles(backup)
+}
+
+// restoreFiles writes the previous content of the files after the release commit was rolled back. The rollback only
+// resets the branch, so the index keeps the content of the release commit, which the user has to unstage.
+func (r *Releaser) restoreFiles(backup []versionfile.Change) {
+	var paths = make([]string, 0, len(backup))
+	for _, c := range backup {
+		paths = append(paths, c.Path)
+	}
+	unstage := "git reset --quiet -- " + strings.Join(paths, " ")
+
+	if err := versionfile.Write(r.opts.Dir, backup); err != nil {
+		r.logger.WithFields(Fields{"error": err}).Errorf("The release commit is rolled back, but the files keep the new version, "+
+			"restore them with \"%v && git checkout -- %v\"", unstage, strings.Join(paths, " "))
+		return
+	}
+	r.logger.WithFields(Fields{
+		"Files": paths,
+	}).Warnf("The release commit is rolled back and the files are restored, unstage them with \"%v\"", unstage)
+}
@@ -0,0 +1,114 @@
+package release
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/exaring/release-cli/pkg/version"
+)
+
+// PlanPromotion plans the promotion of the release candidate to its final version, which tags the commit of the release
+// candidate. The candidate is parsed in the tag format and defaults to the latest version of the release line, which
+// must be a release candidate, if it's empty. If checkCommits is set, the promotion is refused if the current branch
+// has commits on top of the release candidate.
+func (r *Releaser) PlanPromotion(ctx context.Context, candidate string, checkCommits bool) (*Plan, error) {
+	policy, err := BranchPolicy(r.repo, r.opts.Branches)
+	if err != nil {
+		return nil, err
+	}
+	format := r.Format(policy)
+
+	versions := Versions(r.repo, format, "")
+	previous, err := r.releaseCandidate(format, versions, candidate)
+	if err != nil {
+		return nil, err
+	}
+
+	next := make(version.Version, len(previous))
+	copy(next, previous)
+	next[version.Pre] = 0
+
+	if err := policy.Check(previous, next); err != nil {
+		return nil, &PolicyError{Err: err}
+	}
+
+	for _, v := range versions {
+		switch {
+		case version.Compare(v, next) == 0:
+			return nil, fmt.Errorf("the version %v already exists", format.Tag(next))
+		case v.IsReleaseCandidate() && version.DifferingPart(v, previous) == version.Pre &&
+			version.Compare(v, previous) > 0:
+			r.logger.WithFields(Fields{
+				"Candidate": format.Tag(previous),
+				"Newest":    format.Tag(v),
+			}).Warnf("The release candidate isn't the newest one")
+		}
+	}
+
+	// the tag may be in another notation like v2.0.0-RC1
+	candidateTag := VersionTag(r.repo, format, previous)
+	if checkCommits {
+		commits, err := r.repo.Commits(candidateTag, "HEAD")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the commits on top of the release candidate: %w", err)
+		}
+		if len(commits) > 0 {
+			return nil, fmt.Errorf("the current branch has %d commits on top of the release candidate %v",
+				len(commits), candidateTag)
+		}
+	}
+
+	hash, err := r.repo.ResolveRevision(candidateTag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve the release candidate %v: %w", candidateTag, err)
+	}
+	r.logger.WithFields(Fields{
+		"Tag":    format.Tag(next),
+		"Commit": hash,
+	}).Infof("Promote the release candidate")
+
+	plan := &Plan{
+		Format:    format,
+		Previous:  previous,
+		Next:      next,
+		Tag:       format.Tag(next),
+		Commit:    hash,
+		Promotion: true,
+	}
+	if err := CheckRemoteTag(ctx, r.repo, plan.Tag); err != nil {
+		return nil, err
+	}
+
+	plan.Aliases = FloatingAliases(r.repo, format, next, hash, r.opts.Floating, r.opts.FloatingLatest)
+	return plan, nil
+}
+
+// releaseCandidate returns the release candidate of the tag or the latest version of the release line, which must be a
+// release candidate, if the tag is empty.
+func (r *Releaser) releaseCandidate(format version.Format, versions version.Versions, tag string) (version.Version, error) {
+	if tag == "" {
+		latest, err := LatestVersion(r.repo, format, r.opts.Branch, r.opts.Line)
+		if err != nil {
+			return nil, err
+		}
+		if !latest.IsReleaseCandidate() {
+			return nil, fmt.Errorf("the latest version %v isn't a release candidate", format.Tag(latest))
+		}
+		return latest, nil
+	}
+
+	candidate, err := format.Parse(tag)
+	if err != nil {
+		return nil, err
+	}
+	if !candidate.IsReleaseCandidate() {
+		return nil, fmt.Errorf("the version %v isn't a release candidate", format.Tag(candidate))
+	}
+
+	for _, v := range versions {
+		if version.Compare(v, candidate) == 0 {
+			return candidate, nil
+		}
+	}
+	return nil, fmt.Errorf("the release candidate %v doesn't exist", format.Tag(candidate))
+}
@@ -0,0 +1,459 @@
+// Package release computes and publishes the next semantic version tag of a git repository. The Releaser runs the
+// checks, updates the version files and pushes the tag in one transaction, so that other tools can create releases
+// without shelling out to the release command, which is a thin wrapper of this package.
+package release
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/exaring/release-cli/pkg/config"
+	"github.com/exaring/release-cli/pkg/fragment"
+	"github.com/exaring/release-cli/pkg/gomod"
+	"github.com/exaring/release-cli/pkg/repository"
+	"github.com/exaring/release-cli/pkg/transaction"
+	"github.com/exaring/release-cli/pkg/version"
+	"github.com/exaring/release-cli/pkg/versionfile"
+)
+
+// Logger logs the progress of a release. It's the logger of the transaction, which publishes the release.
+type Logger = transaction.Logger
+
+// Fields are the details of a log entry.
+type Fields = transaction.Fields
+
+// Repository is an abstraction of the version control system client
+type Repository interface {
+	// LatestCommitHash returns the latest commit hash of the repository. In case of an error the result is empty.
+	LatestCommitHash() string
+	// Files lists all files of the given revision read from the local object store.
+	Files(revision string) ([]repository.File, error)
+	// ExistsTag validates the parameter version and returns the existence of the repository tag.
+	ExistsTag(version string) (bool, error)
+	// Tags lists all existing tags of the repository.
+	Tags() []string
+	// BranchTags lists all existing tags related to commits of the given branch.
+	BranchTags(branchName string) []string
+	// CurrentBranch returns the name of the checked out branch.
+	CurrentBranch() (string, error)
+	// RemoteURL returns the URL of the origin.
+	RemoteURL() (string, error)
+	// ReachableTags lists all existing tags related to commits reachable from the given revision.
+	ReachableTags(revision string) []string
+	// Commits lists all commits reachable from the revision to but not from the revision from, newest first.
+	Commits(from, to string) ([]repository.Commit, error)
+	// IsSafe validate the state of the repository and returns an error if the repository is unsafe like include uncommitted files
+	// or the local branch is behind the origin.
+	IsSafe(ctx context.Context) error
+	// CreateTag creates a local version control system tag.
+	CreateTag(tag string) error
+	// ResolveRevision returns the commit hash of the given revision.
+	ResolveRevision(revision string) (string, error)
+	// DeleteTag deletes a local version control system  tag.
+	DeleteTag(tag string) error
+	// Branches lists the names of the local branches and of the branches of the remote.
+	Branches() ([]string, error)
+	// RemoteReferences lists the references of the remote with their commit hashes.
+	RemoteReferences(ctx context.Context) (map[string]string, error)
+	// FetchTags fetches all tags of the remote.
+	FetchTags(ctx context.Context) error
+	// CreateOrphanCommit creates a commit without parents and files and returns its hash.
+	CreateOrphanCommit(message string) (string, error)
+	// CreateTagObject creates the object of an annotated tag of the commit hash and returns its hash.
+	CreateTagObject(name, hash, message string) (string, error)
+	// SetReference creates or moves the reference with the full name to the commit hash.
+	SetReference(name, hash string) error
+	// RemoveReference deletes the reference with the full name.
+	RemoveReference(name string) error
+	// Commit adds the given files to the index and commits them. It returns the hash of the new commit.
+	Commit(message string, files ...string) (string, error)
+	// Push pushes the given ref specs of the local repo to the origin. Without ref specs all tags are pushed.
+	Push(ctx context.Context, refSpecs ...string) error
+}
+
+// Options configures a release. The zero value releases the next patch version of all tags of the repository in the
+// default tag format without any checks.
+type Options struct {
+	// Dir is the root directory of the working tree, which contains the version files and the change fragments.
+	Dir string
+	// Format is the notation of the version tags. It defaults to version.DefaultFormat.
+	Format version.Format
+	// Branch limits the tracked tags to the tags of the given branch.
+	Branch string
+	// Line limits the tracked tags to the release line.
+	Line *version.Line
+	// Branches lists the release policies of the branches which may produce releases. All branches are allowed if
+	// it's empty.
+	Branches []config.Branch
+	// Major, Minor, Patch and Pre select the increased version part. The part of the change fragments or the patch
+	// part is increased if none is selected.
+	Major, Minor, Patch, Pre bool
+	// Force releases a repository in an unsafe state.
+	Force bool
+	// APICheck compares the exported Go API with the previous version and refuses incompatible releases.
+	APICheck bool
+	// ZipCheck validates the Go module zip of the new version.
+	ZipCheck bool
+	// Floating moves the alias tags of the major and minor line to final releases.
+	Floating bool
+	// FloatingLatest moves the latest alias tag to the newest final release.
+	FloatingLatest bool
+	// CutBranch creates the maintenance branch of the new release line, which is named by BranchTemplate.
+	CutBranch bool
+	// BranchTemplate is the name template of maintenance branches. It defaults to version.DefaultBranchTemplate.
+	BranchTemplate string
+	// Retry recomputes the version with the remote tags up to the given times if the tag already exists on the remote.
+	Retry int
+	// VersionFiles lists the files which store the version. They are updated and committed before tagging.
+	VersionFiles []config.VersionFile
+	// GenVersionFile is the path of the generated Go version file.
+	GenVersionFile string
+	// Changelog is the path of the changelog file, which the notes of the change fragments are added to.
+	Changelog string
+	// Message is the message of an annotated tag. An empty message creates a lightweight tag.
+	Message string
+	// Journal is the file the transaction of the release is recorded in. The transaction isn't recorded if it's empty.
+	Journal string
+	// Dry doesn't write any file. The repository of a dry-run should be a no-operation client as well.
+	Dry bool
+	// Hook runs the hook steps of the transaction with the details of the release. A failing hook aborts the release.
+	Hook func(ctx context.Context, name string, result *Result) error
+}
+
+// Plan is the computed release, which is published by Execute. It's a release, a promotion or a retraction.
+type Plan struct {
+	// Format is the notation of the tags, whose pre-release label is the channel of the branch policy.
+	Format version.Format
+	// Previous is the latest version and Next is the new version.
+	Previous, Next version.Version
+	// Tag is the tag of the new version.
+	Tag string
+	// Commit is the hash of the current HEAD or of the release candidate of a promotion.
+	Commit string
+	// Branch is the name of the maintenance branch, which is cut with the release.
+	Branch string
+	// Aliases are the floating alias tags, which are moved to the tagged commit of the release.
+	Aliases []transaction.Ref
+	// Fragments are the change fragments of the release.
+	Fragments []fragment.Fragment
+	// Promotion tags the commit of the release candidate Previous with its final version Next. The version files
+	// aren't changed.
+	Promotion bool
+	// Retraction is added to the go.mod file by the release commit instead of the changes of the version files.
+	Retraction *gomod.Retraction
+
+	// headHash is the h1: hash of the module zip of the current HEAD.
+	headHash string
+}
+
+// Bump returns the name of the increased version part.
+func (p *Plan) Bump() string {
+	return version.PartName(version.DifferingPart(p.Previous, p.Next))
+}
+
+// Result is the published release.
+type Result struct {
+	*Plan
+	// Commit is the hash of the tagged commit, which is the release commit of the version files if there are any.
+	Commit string
+	// Notes are the release notes of the change fragments.
+	Notes string
+	// LDFlags are the linker flags, which inject the details of the generated version file.
+	LDFlags string
+	// ModuleHash is the h1: hash of the module zip of the tagged commit, which is only computed by the zip check. It's
+	// empty in the dry-run mode if the version files change, because the release commit isn't created.
+	ModuleHash string
+}
+
+// Releaser creates the releases of a repository.
+type Releaser struct {
+	repo   Repository
+	opts   Options
+	logger Logger
+}
+
+// New returns the releaser of the repository. The logs are discarded if the logger is nil.
+func New(repo Repository, opts Options, logger Logger) *Releaser {
+	if opts.Format == (version.Format{}) {
+		opts.Format = version.DefaultFormat
+	}
+	if opts.BranchTemplate == "" {
+		opts.BranchTemplate = version.DefaultBranchTemplate
+	}
+	if logger == nil {
+		logger = discard{}
+	}
+	return &Releaser{repo: repo, opts: opts, logger: logger}
+}
+
+// Format returns the tag format of the branch policy, whose channel replaces the pre-release label.
+func (r *Releaser) Format(policy config.Branch) version.Format {
+	format := r.opts.Format
+	if policy.Channel != "" {
+		format.PreLabel = policy.Channel
+	}
+	return format
+}
+
+// Check returns the release policy of the current branch. It returns a PolicyError if the branch doesn't produce
+// releases and an UnsafeError if the repository is in an unsafe state and the release isn't forced.
+func (r *Releaser) Check(ctx context.Context) (config.Branch, error) {
+	policy, err := BranchPolicy(r.repo, r.opts.Branches)
+	if err != nil {
+		return config.Branch{}, err
+	}
+
+	if err := r.repo.IsSafe(ctx); !r.opts.Force && err != nil {
+		return config.Branch{}, &UnsafeError{Err: err}
+	}
+	return policy, nil
+}
+
+// Latest returns the latest version of the release line or of the tracked tags. It returns a NoVersionError if there
+// is no version yet.
+func (r *Releaser) Latest(ctx context.Context) (version.Version, error) {
+	policy, err := BranchPolicy(r.repo, r.opts.Branches)
+	if err != nil {
+		return nil, err
+	}
+	return LatestVersion(r.repo, r.Format(policy), r.opts.Branch, r.opts.Line)
+}
+
+// Plan checks the repository and computes the next version. The version is recomputed with the tags of the remote
+// up to the retry times if its tag already exists on the remote, otherwise a TagExistsError is returned.
+func (r *Releaser) Plan(ctx context.Context) (*Plan, error) {
+	policy, err := r.Check(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	fragments, err := fragment.Read(r.opts.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for attempt := 0; ; attempt++ {
+		plan, err := r.plan(policy, fragments)
+		if err != nil {
+			return nil, err
+		}
+
+		err = CheckRemoteTag(ctx, r.repo, plan.Tag)
+		if err == nil {
+			return plan, nil
+		}
+		if attempt >= r.opts.Retry {
+			return nil, err
+		}
+		r.logger.WithFields(Fields{"error": err}).Warnf("Recompute the version with the tags of the remote")
+		if err := r.repo.FetchTags(ctx); err != nil {
+			return nil, fmt.Errorf("failed to fetch the tags: %w", err)
+		}
+	}
+}
+
+// plan computes the next version of the release line from the latest version and runs the checks of the options.
+func (r *Releaser) plan(policy config.Branch, fragments []fragment.Fragment) (*Plan, error) {
+	r.logger.Debugf("Analyse the git repository")
+
+	format := r.Format(policy)
+	previous, err := LatestVersion(r.repo, format, r.opts.Branch, r.opts.Line)
+	if err != nil {
+		return nil, err
+	}
+	r.logger.WithFields(Fields{
+		"Tag": format.Tag(previous),
+	}).Debugf("Detect latest tag of the repository")
+
+	next := make(version.Version, len(previous))
+	copy(next, previous)
+
+	major, minor, patch := r.fragmentBump(previous, fragments)
+	if r.opts.APICheck {
+		if err := CheckAPI(r.logger, r.repo, format, previous, major, minor, patch); err != nil {
+			return nil, err
+		}
+	}
+
+	next.Increase(major, minor, patch, r.opts.Pre || policy.Channel != "")
+	r.logger.WithFields(Fields{
+		"Tag": format.Tag(next),
+	}).Infof("Create new releasing version")
+
+	if err := policy.Check(previous, next); err != nil {
+		return nil, &PolicyError{Err: err}
+	}
+
+	if r.opts.Line != nil {
+		if err := CheckLine(r.repo, format, *r.opts.Line, next); err != nil {
+			return nil, err
+		}
+	}
+
+	var headHash string
+	if r.opts.ZipCheck {
+		// the release commit of the version files doesn't exist yet, its hash is computed by Execute
+		if headHash, err = CheckModuleZip(r.logger, r.repo, format, next, "HEAD"); err != nil {
+			return nil, err
+		}
+	}
+
+	plan := &Plan{
+		Format:    format,
+		Previous:  previous,
+		Next:      next,
+		Tag:       format.Tag(next),
+		Commit:    r.repo.LatestCommitHash(),
+		Fragments: fragments,
+		headHash:  headHash,
+	}
+
+	plan.Aliases = FloatingAliases(r.repo, format, next, plan.Commit, r.opts.Floating, r.opts.FloatingLatest)
+
+	if r.opts.CutBranch {
+		if plan.Branch, err = MaintenanceBranch(r.repo, r.opts.BranchTemplate, next); err != nil {
+			return nil, err
+		}
+	}
+
+	return plan, nil
+}
+
+// Execute publishes the planned release: the version files are updated and committed, the tag and the maintenance
+// branch are set and pushed and the floating alias tags are moved. The references are rolled back if a step fails.
+func (r *Releaser) Execute(ctx context.Context, plan *Plan) (*Result, error) {
+	result := &Result{Plan: plan, Commit: plan.Commit}
+
+	tx, err := transaction.Begin(r.repo, r.logger, r.opts.Journal, plan.Tag)
+	if err != nil {
+		return nil, err
+	}
+	tx.SetHook(func(ctx context.Context, name string) error {
+		if r.opts.Hook == nil {
+			return nil
+		}
+		return r.opts.Hook(ctx, name, result)
+	})
+
+	var changes []versionfile.Change
+	message := fmt.Sprintf("Release %v", plan.Tag)
+	switch {
+	case plan.Promotion:
+		// the commit of the release candidate is tagged as it is
+	case plan.Retraction != nil:
+		if changes, err = r.retractionChanges(plan); err != nil {
+			return nil, err
+		}
+		message = fmt.Sprintf("Retract %v", plan.Retraction)
+		if plan.Retraction.Rationale != "" {
+			message += "\n\n" + plan.Retraction.Rationale
+		}
+	default:
+		if changes, err = r.releaseChanges(plan, result); err != nil {
+			return nil, err
+		}
+	}
+
+	var refs []transaction.Ref
+	releaseCommit, backup, err := r.commitFiles(message, plan, changes)
+	if err != nil {
+		return nil, err
+	}
+	if releaseCommit != nil {
+		// the commit moved the branch already, which is reset if a hook or push fails
+		tx.Add(transaction.Local, *releaseCommit)
+		refs = append(refs, *releaseCommit)
+		result.Commit = releaseCommit.Hash
+	}
+
+	if len(changes) == 0 {
+		result.ModuleHash = plan.headHash
+	}
+	if r.opts.ZipCheck && releaseCommit != nil {
+		if result.ModuleHash, err = CheckModuleZip(r.logger, r.repo, plan.Format, plan.Next, result.Commit); err != nil {
+			r.resetCommit(*releaseCommit, backup)
+			return nil, err
+		}
+	}
+
+	if plan.Branch != "" {
+		refs = append(refs, transaction.Ref{Name: "refs/heads/" + plan.Branch, Hash: result.Commit})
+	}
+	target := result.Commit
+	if r.opts.Message != "" {
+		if target, err = r.repo.CreateTagObject(plan.Tag, result.Commit, r.opts.Message); err != nil {
+			if releaseCommit != nil {
+				r.resetCommit(*releaseCommit, backup)
+			}
+			return nil, fmt.Errorf("failed to create the annotated tag: %w", err)
+		}
+	}
+	// the aliases of the plan point at the previous HEAD, they are moved to the tagged commit like the tag
+	var aliases = make([]transaction.Ref, len(plan.Aliases))
+	for i, a := range plan.Aliases {
+		aliases[i] = transaction.Ref{Name: a.Name, Hash: result.Commit, Previous: a.Previous}
+	}
+	if err := Publish(ctx, tx, plan.Tag, target, refs, aliases); err != nil {
+		if releaseCommit != nil {
+			r.restoreFiles(backup)
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+// releaseChanges returns the changes of the version files, the change fragments and the generated version file and
+// adds the release notes and the linker flags to the result.
+func (r *Releaser) releaseChanges(plan *Plan, result *Result) ([]versionfile.Change, error) {
+	changes, err := r.versionFileChanges(plan)
+	if err != nil {
+		return nil, err
+	}
+	notes, fragmentChanges, err := r.fragmentChanges(plan)
+	if err != nil {
+		return nil, err
+	}
+	changes = append(changes, fragmentChanges...)
+	result.Notes = notes
+	if r.opts.GenVersionFile != "" {
+		change, ldflags, err := r.generateVersionFile(plan)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+		result.LDFlags = ldflags
+		r.logger.WithFields(Fields{
+			"LDFlags": ldflags,
+		}).Infof("Generate the version file, the linker flags inject the same details")
+	}
+	return changes, nil
+}
+
+// Publish adds the steps of the release to the transaction and runs it. The tag is set to the hash of the commit or
+// of an annotated tag object and the given references are set between the pre-tag and post-tag hook and pushed in one
+// push. Afterwards the floating alias tags are moved and force-pushed. All completed steps are undone in reverse order
+// and a PublishError is returned if a step fails. The rollback never resets a remote branch, so a pushed release commit
+// stays on the remote.
+func Publish(ctx context.Context, tx *transaction.Transaction, tag, hash string, refs, aliases []transaction.Ref) error {
+	refs = append([]transaction.Ref{{Name: "refs/tags/" + tag, Hash: hash}}, refs...)
+	tx.AddHook(config.HookPreTag)
+	tx.Add(transaction.Local, refs...)
+	tx.AddHook(config.HookPostTag)
+	tx.Add(transaction.Remote, refs...)
+	tx.Add(transaction.Local, aliases...)
+	tx.Add(transaction.Remote, aliases...)
+
+	if err := tx.Run(ctx); err != nil {
+		return &PublishError{Tag: tag, Err: err}
+	}
+	return nil
+}
+
+// discard is the logger, which drops all entries.
+type discard struct{}
+
+func (d discard) WithFields(Fields) Logger                { return d }
+func (discard) Debugf(format string, args ...interface{}) {}
+func (discard) Infof(format string, args ...interface{})  {}
+func (discard) Warnf(format string, args ...interface{})  {}
+func (discard) Errorf(format string, args ...interface{}) {}
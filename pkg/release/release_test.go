@@ -0,0 +1,394 @@
+package release
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/exaring/release-cli/pkg/config"
+	"github.com/exaring/release-cli/pkg/gomod"
+	"github.com/exaring/release-cli/pkg/modzip"
+	"github.com/exaring/release-cli/pkg/repository"
+	"github.com/exaring/release-cli/pkg/version"
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+)
+
+// newRepository creates a repository with a bare remote as origin. The initial commit is tagged with the given tags
+// and pushed with the tags.
+func newRepository(t *testing.T, dir string, tags ...string) *repository.Git {
+	remote := filepath.Join(dir, "remote.git")
+	_, err := git.PlainInit(remote, true)
+	assert.NoError(t, err)
+
+	path := filepath.Join(dir, "local")
+	r, err := git.PlainInit(path, false)
+	assert.NoError(t, err)
+	_, err = r.CreateRemote(&gitconfig.RemoteConfig{Name: "origin", URLs: []string{remote}})
+	assert.NoError(t, err)
+	cfg, err := r.Config()
+	assert.NoError(t, err)
+	cfg.Raw.Section("user").SetOption("name", "Release").SetOption("email", "release@example.com")
+	assert.NoError(t, r.Storer.SetConfig(cfg))
+
+	repo, err := repository.New(path)
+	assert.NoError(t, err)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(path, "VERSION"), []byte("1.2.3\n"), 0644))
+	hash, err := repo.Commit("Initial commit", "VERSION")
+	assert.NoError(t, err)
+	for _, tag := range tags {
+		assert.NoError(t, repo.SetReference("refs/tags/"+tag, hash))
+	}
+	assert.NoError(t, repo.Push(context.Background(), "refs/heads/master:refs/heads/master", "refs/tags/*:refs/tags/*"))
+	return repo
+}
+
+func TestLatestVersion(t *testing.T) {
+	dir, err := ioutil.TempDir("", "release")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	repo := newRepository(t, dir, "v1.2.3", "v1.3.0-RC1", "v2.0.0", "v2", "latest")
+
+	tests := []struct {
+		name    string
+		line    string
+		want    string
+		wantErr string
+	}{
+		{name: "all tags", want: "v2.0.0"},
+		{name: "line", line: "v1", want: "v1.3.0-RC.1"},
+		{name: "minor line", line: "v1.2", want: "v1.2.3"},
+		{name: "empty line", line: "v3", wantErr: "the release line v3 has no versions"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var line *version.Line
+			if tt.line != "" {
+				l, err := version.ParseLine(tt.line)
+				assert.NoError(t, err)
+				line = &l
+			}
+
+			v, err := LatestVersion(repo, version.DefaultFormat, "", line)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				var noVersion *NoVersionError
+				assert.True(t, errors.As(err, &noVersion))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, v.String())
+		})
+	}
+}
+
+func TestParseVersions(t *testing.T) {
+	tags := []string{"refs/tags/v1.2.0", "refs/tags/v1.3.0-beta.1", "refs/tags/v1.3.0-RC1", "refs/tags/v1"}
+
+	versions := ParseVersions(version.DefaultFormat, tags)
+	assert.Equal(t, version.Versions{{1, 2, 0, 0}, {1, 3, 0, 1}}, versions, "the tags of the beta channel are skipped")
+
+	versions = ParseVersions(version.Format{Prefix: "v", PreLabel: "beta"}, tags)
+	assert.Equal(t, version.Versions{{1, 2, 0, 0}, {1, 3, 0, 1}}, versions, "the release candidates are skipped")
+}
+
+func TestVersionTag(t *testing.T) {
+	dir, err := ioutil.TempDir("", "release")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	repo := newRepository(t, dir, "v1.2.0", "v1.3.0-RC1", "v1.3.0-RC.2")
+
+	assert.Equal(t, "v1.3.0-RC1", VersionTag(repo, version.DefaultFormat, version.Version{1, 3, 0, 1}))
+	assert.Equal(t, "v1.3.0-RC.2", VersionTag(repo, version.DefaultFormat, version.Version{1, 3, 0, 2}))
+	assert.Equal(t, "v1.3.0", VersionTag(repo, version.DefaultFormat, version.Version{1, 3, 0, 0}))
+}
+
+func TestCheckModuleZip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "release")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	repo := newRepository(t, dir, "v1.2.3")
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(repo.Path(), "go.mod"), []byte("module example.com/app\n"), 0644))
+	_, err = repo.Commit("Add the go.mod file", "go.mod")
+	assert.NoError(t, err)
+
+	files, err := repo.Files("HEAD")
+	assert.NoError(t, err)
+	want, err := modzip.Check(files, "v1.3.0-beta.1")
+	assert.NoError(t, err)
+
+	format := version.Format{Prefix: "v", PreLabel: "beta"}
+	hash, err := CheckModuleZip(discard{}, repo, format, version.Version{1, 3, 0, 1}, "HEAD")
+	assert.NoError(t, err)
+	assert.Equal(t, want.Hash, hash)
+
+	_, err = CheckModuleZip(discard{}, repo, format, version.Version{1, 3, 0, 1}, "v1.2.3")
+	assert.EqualError(t, err, "failed to create the module zip: the files don't contain a go.mod file")
+}
+
+func TestReleaser_PlanAfterChannel(t *testing.T) {
+	dir, err := ioutil.TempDir("", "release")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	// a beta of the next minor version was released from the develop branch
+	repo := newRepository(t, dir, "v1.2.0", "v1.3.0-beta.1")
+
+	plan, err := New(repo, Options{Dir: repo.Path(), Minor: true}, nil).Plan(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "v1.2.0", plan.Format.Tag(plan.Previous))
+	assert.Equal(t, "v1.3.0", plan.Tag)
+}
+
+func TestReleaser_Plan(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		dirty   bool
+		want    string
+		wantErr interface{}
+	}{
+		{name: "patch", want: "v1.2.4"},
+		{name: "minor", opts: Options{Minor: true}, want: "v1.3.0"},
+		{name: "channel", opts: Options{Branches: []config.Branch{{Name: "master", Channel: "beta"}}},
+			want: "v1.2.4-beta.1"},
+		{name: "prefix", opts: Options{Format: version.Format{Prefix: "", PreLabel: "RC"}}, want: "1.2.4"},
+		{name: "forbidden branch", opts: Options{Branches: []config.Branch{{Name: "main"}}},
+			wantErr: new(*PolicyError)},
+		{name: "forbidden part", opts: Options{Major: true,
+			Branches: []config.Branch{{Name: "master", Parts: []string{"minor", "patch"}}}},
+			wantErr: new(*PolicyError)},
+		{name: "dirty", dirty: true, wantErr: new(*UnsafeError)},
+		{name: "forced", opts: Options{Force: true}, dirty: true, want: "v1.2.4"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "release")
+			assert.NoError(t, err)
+			defer os.RemoveAll(dir)
+			repo := newRepository(t, dir, "v1.2.3", "1.2.3")
+			if tt.dirty {
+				assert.NoError(t, ioutil.WriteFile(filepath.Join(repo.Path(), "VERSION"), []byte("dirty"), 0644))
+			}
+
+			tt.opts.Dir = repo.Path()
+			plan, err := New(repo, tt.opts, nil).Plan(context.Background())
+			if tt.wantErr != nil {
+				assert.True(t, errors.As(err, tt.wantErr), "unexpected error %v", err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, plan.Tag)
+			assert.Equal(t, repo.LatestCommitHash(), plan.Commit)
+		})
+	}
+}
+
+func TestReleaser_PlanRetry(t *testing.T) {
+	dir, err := ioutil.TempDir("", "release")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	repo := newRepository(t, dir, "v1.2.3")
+	// a concurrent release pushed v1.2.4 on a commit, which isn't fetched yet
+	other, err := git.PlainClone(filepath.Join(dir, "other"), false, &git.CloneOptions{
+		URL: filepath.Join(dir, "remote.git"),
+	})
+	assert.NoError(t, err)
+	w, err := other.Worktree()
+	assert.NoError(t, err)
+	hash, err := w.Commit("Concurrent release", &git.CommitOptions{
+		Author: &object.Signature{Name: "Other", Email: "other@example.com", When: time.Now()},
+	})
+	assert.NoError(t, err)
+	_, err = other.CreateTag("v1.2.4", hash, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, other.Push(&git.PushOptions{RefSpecs: []gitconfig.RefSpec{"refs/tags/v1.2.4:refs/tags/v1.2.4"}}))
+
+	_, err = New(repo, Options{Dir: repo.Path()}, nil).Plan(context.Background())
+	assert.EqualError(t, err, "the tag v1.2.4 already exists on the remote")
+	var exists *TagExistsError
+	assert.True(t, errors.As(err, &exists))
+
+	plan, err := New(repo, Options{Dir: repo.Path(), Retry: 1}, nil).Plan(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "v1.2.5", plan.Tag)
+}
+
+func TestReleaser_Execute(t *testing.T) {
+	dir, err := ioutil.TempDir("", "release")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	repo := newRepository(t, dir, "v1.2.3")
+
+	var hooks []string
+	releaser := New(repo, Options{
+		Dir:          repo.Path(),
+		Minor:        true,
+		VersionFiles: []config.VersionFile{{Path: "VERSION"}},
+		Hook: func(ctx context.Context, name string, result *Result) error {
+			hooks = append(hooks, name+" "+result.Tag)
+			return nil
+		},
+	}, nil)
+	plan, err := releaser.Plan(context.Background())
+	assert.NoError(t, err)
+
+	result, err := releaser.Execute(context.Background(), plan)
+	assert.NoError(t, err)
+	assert.NotEqual(t, plan.Commit, result.Commit)
+	assert.Equal(t, "minor", result.Bump())
+	assert.Equal(t, []string{config.HookPreTag + " v1.3.0", config.HookPostTag + " v1.3.0"}, hooks)
+
+	data, err := ioutil.ReadFile(filepath.Join(repo.Path(), "VERSION"))
+	assert.NoError(t, err)
+	assert.Equal(t, "1.3.0\n", string(data))
+
+	refs, err := repo.RemoteReferences(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, result.Commit, refs["refs/tags/v1.3.0"])
+	assert.Equal(t, result.Commit, refs["refs/heads/master"])
+
+	latest, err := releaser.Latest(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "v1.3.0", latest.String())
+}
+
+func TestReleaser_ExecuteHookFailure(t *testing.T) {
+	dir, err := ioutil.TempDir("", "release")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	repo := newRepository(t, dir, "v1.2.3")
+
+	releaser := New(repo, Options{
+		Dir: repo.Path(),
+		Hook: func(ctx context.Context, name string, result *Result) error {
+			if name == config.HookPostTag {
+				return errors.New("hook failed")
+			}
+			return nil
+		},
+	}, nil)
+	plan, err := releaser.Plan(context.Background())
+	assert.NoError(t, err)
+
+	_, err = releaser.Execute(context.Background(), plan)
+	assert.EqualError(t, err, "failed to publish v1.2.4: hook failed")
+	var publish *PublishError
+	assert.True(t, errors.As(err, &publish))
+
+	exists, err := repo.ResolveRevision("refs/tags/v1.2.4")
+	assert.Error(t, err, exists)
+}
+
+func TestReleaser_ExecuteRejectedPush(t *testing.T) {
+	dir, err := ioutil.TempDir("", "release")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	repo := newRepository(t, dir, "v1.2.3")
+
+	releaser := New(repo, Options{Dir: repo.Path(), VersionFiles: []config.VersionFile{{Path: "VERSION"}}}, nil)
+	plan, err := releaser.Plan(context.Background())
+	assert.NoError(t, err)
+
+	// a teammate pushes to master after the release was planned, so the push of the release commit is rejected
+	other, err := git.PlainClone(filepath.Join(dir, "other"), false, &git.CloneOptions{
+		URL: filepath.Join(dir, "remote.git"),
+	})
+	assert.NoError(t, err)
+	w, err := other.Worktree()
+	assert.NoError(t, err)
+	teammate, err := w.Commit("Teammate change", &git.CommitOptions{
+		Author: &object.Signature{Name: "Other", Email: "other@example.com", When: time.Now()},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, other.Push(&git.PushOptions{}))
+
+	_, err = releaser.Execute(context.Background(), plan)
+	var publish *PublishError
+	assert.True(t, errors.As(err, &publish), "unexpected error %v", err)
+
+	refs, err := repo.RemoteReferences(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, teammate.String(), refs["refs/heads/master"], "the commit of the teammate is kept")
+	assert.NotContains(t, refs, "refs/tags/v1.2.4")
+	assert.Equal(t, plan.Commit, repo.LatestCommitHash())
+	data, err := ioutil.ReadFile(filepath.Join(repo.Path(), "VERSION"))
+	assert.NoError(t, err)
+	assert.Equal(t, "1.2.3\n", string(data))
+}
+
+func TestReleaser_PlanPromotion(t *testing.T) {
+	tests := []struct {
+		name      string
+		tags      []string
+		candidate string
+		want      string
+		wantErr   string
+	}{
+		{name: "latest", tags: []string{"v1.2.3", "v1.3.0-RC1", "v1.3.0-RC2"}, want: "v1.3.0"},
+		{name: "older candidate", tags: []string{"v1.2.3", "v1.3.0-RC1", "v1.3.0-RC2"}, candidate: "v1.3.0-RC1",
+			want: "v1.3.0"},
+		{name: "final", tags: []string{"v1.2.3"}, wantErr: "the latest version v1.2.3 isn't a release candidate"},
+		{name: "missing", tags: []string{"v1.2.3"}, candidate: "v1.3.0-RC1",
+			wantErr: "the release candidate v1.3.0-RC.1 doesn't exist"},
+		{name: "promoted", tags: []string{"v1.3.0-RC1", "v1.3.0"}, candidate: "v1.3.0-RC1",
+			wantErr: "the version v1.3.0 already exists"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "release")
+			assert.NoError(t, err)
+			defer os.RemoveAll(dir)
+			repo := newRepository(t, dir, tt.tags...)
+
+			plan, err := New(repo, Options{Dir: repo.Path()}, nil).PlanPromotion(context.Background(), tt.candidate, true)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, plan.Promotion)
+			assert.Equal(t, tt.want, plan.Tag)
+			assert.Equal(t, "pre", (&Result{Plan: plan}).Bump())
+			assert.Equal(t, repo.LatestCommitHash(), plan.Commit)
+		})
+	}
+}
+
+func TestReleaser_ExecuteRetraction(t *testing.T) {
+	dir, err := ioutil.TempDir("", "release")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	repo := newRepository(t, dir, "v1.2.2", "v1.2.3")
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(repo.Path(), "go.mod"), []byte("module example.com/app\n"), 0644))
+	_, err = repo.Commit("Add go.mod", "go.mod")
+	assert.NoError(t, err)
+
+	releaser := New(repo, Options{Dir: repo.Path()}, nil)
+	_, err = releaser.PlanRetraction(context.Background(), gomod.Retraction{Low: "1.2.3"})
+	assert.EqualError(t, err, `failed to retract the version: invalid semantic version "1.2.3"`)
+
+	plan, err := releaser.PlanRetraction(context.Background(), gomod.Retraction{Low: "v1.2.3", Rationale: "broken"})
+	assert.NoError(t, err)
+	assert.Equal(t, "v1.2.4", plan.Tag)
+
+	result, err := releaser.Execute(context.Background(), plan)
+	assert.NoError(t, err)
+	assert.NotEqual(t, plan.Commit, result.Commit)
+
+	data, err := ioutil.ReadFile(filepath.Join(repo.Path(), "go.mod"))
+	assert.NoError(t, err)
+	retractions, err := gomod.Retractions(data)
+	assert.NoError(t, err)
+	assert.Equal(t, []gomod.Retraction{{Low: "v1.2.3", High: "v1.2.3", Rationale: "broken"}}, retractions)
+
+	refs, err := repo.RemoteReferences(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, result.Commit, refs["refs/tags/v1.2.4"])
+	assert.Equal(t, result.Commit, refs["refs/heads/master"])
+}
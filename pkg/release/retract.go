@@ -0,0 +1,69 @@
+package release
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/exaring/release-cli/pkg/gomod"
+	"github.com/exaring/release-cli/pkg/version"
+	"github.com/exaring/release-cli/pkg/versionfile"
+)
+
+// PlanRetraction checks the repository and plans the next patch version, whose release commit adds the retraction to
+// the go.mod file. It returns an error if the go.mod file can't retract the versions.
+func (r *Releaser) PlanRetraction(ctx context.Context, retraction gomod.Retraction) (*Plan, error) {
+	policy, err := r.Check(ctx)
+	if err != nil {
+		return nil, err
+	}
+	format := r.Format(policy)
+
+	plan := &Plan{Format: format, Retraction: &retraction}
+	if _, err := r.retractionChanges(plan); err != nil {
+		return nil, err
+	}
+
+	previous, err := LatestVersion(r.repo, format, r.opts.Branch, r.opts.Line)
+	if err != nil {
+		return nil, err
+	}
+	next := make(version.Version, len(previous))
+	copy(next, previous)
+	next.Increase(false, false, true, false)
+
+	if err := policy.Check(previous, next); err != nil {
+		return nil, &PolicyError{Err: err}
+	}
+	if r.opts.Line != nil {
+		if err := CheckLine(r.repo, format, *r.opts.Line, next); err != nil {
+			return nil, err
+		}
+	}
+
+	plan.Previous, plan.Next, plan.Tag, plan.Commit = previous, next, format.Tag(next), r.repo.LatestCommitHash()
+	if err := CheckRemoteTag(ctx, r.repo, plan.Tag); err != nil {
+		return nil, err
+	}
+	r.logger.WithFields(Fields{
+		"Retract": retraction,
+		"Tag":     plan.Tag,
+	}).Infof("Create new retracting version")
+
+	return plan, nil
+}
+
+// retractionChanges returns the change of the go.mod file, which adds the retraction of the plan.
+func (r *Releaser) retractionChanges(plan *Plan) ([]versionfile.Change, error) {
+	data, err := ioutil.ReadFile(filepath.Join(r.opts.Dir, gomod.FileName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the go.mod file: %w", err)
+	}
+
+	data, err = gomod.Retract(data, *plan.Retraction)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retract the version: %w", err)
+	}
+	return []versionfile.Change{{Path: gomod.FileName, Data: data}}, nil
+}
@@ -0,0 +1,164 @@
+package release
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/exaring/release-cli/pkg/config"
+	"github.com/exaring/release-cli/pkg/transaction"
+	"github.com/exaring/release-cli/pkg/version"
+)
+
+// Versions returns the sorted versions of all tags of the given branch or of the whole repository if the branch name
+// is empty.
+func Versions(vc Repository, format version.Format, branchName string) version.Versions {
+	var tags = vc.Tags()
+	if branchName != "" {
+		tags = vc.BranchTags(branchName)
+	}
+
+	return ParseVersions(format, tags)
+}
+
+// ParseVersions parses the sorted versions of the tags in the tag format. Tags of other formats or pre-release labels,
+// e.g. of a release channel, floating alias tags and tags without version are skipped.
+func ParseVersions(format version.Format, tags []string) version.Versions {
+	var versions version.Versions
+	for _, tag := range tags {
+		if format.IsAlias(tag) {
+			continue
+		}
+		o, err := format.Parse(tag)
+		if err != nil {
+			// tags of other formats, channels or no version at all
+			continue
+		}
+		versions = append(versions, o)
+	}
+
+	sort.Sort(versions)
+
+	return versions
+}
+
+// VersionTag returns the name of the existing tag of the version, which may use another notation of the pre-release
+// than the tag format, e.g. v2.0.0-RC1 instead of v2.0.0-RC.1. It returns the tag of the format if the version has no
+// other tag.
+func VersionTag(vc Repository, format version.Format, v version.Version) string {
+	for _, tag := range vc.Tags() {
+		if i := strings.LastIndex(tag, "refs/tags/"); i >= 0 {
+			tag = tag[i+len("refs/tags/"):]
+		}
+		if tag == format.Tag(v) || format.IsAlias(tag) {
+			continue
+		}
+		if o, err := format.Parse(tag); err == nil && version.Compare(o, v) == 0 {
+			return tag
+		}
+	}
+	return format.Tag(v)
+}
+
+// LatestVersion returns the latest version of the release line or of the whole branch if the line is nil. All tags
+// of the repository are tracked if the branch name is empty. It returns a NoVersionError if there is no version.
+func LatestVersion(vc Repository, format version.Format, branchName string, line *version.Line) (version.Version, error) {
+	versions := Versions(vc, format, branchName)
+	for i := len(versions) - 1; i >= 0; i-- {
+		if line == nil || line.Contains(versions[i]) {
+			return versions[i], nil
+		}
+	}
+	return nil, &NoVersionError{Branch: branchName, Line: line}
+}
+
+// CheckLine returns a PolicyError if the next version doesn't belong to the release line, already exists in the
+// repository or skips over an existing newer version of the line.
+func CheckLine(vc Repository, format version.Format, line version.Line, next version.Version) error {
+	if err := line.Check(next, Versions(vc, format, "")); err != nil {
+		return &PolicyError{Err: err}
+	}
+	return nil
+}
+
+// CheckRemoteTag returns a TagExistsError if the tag already exists on the remote.
+func CheckRemoteTag(ctx context.Context, vc Repository, tag string) error {
+	refs, err := vc.RemoteReferences(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list the remote references: %w", err)
+	}
+
+	if _, ok := refs["refs/tags/"+tag]; ok {
+		return &TagExistsError{Tag: tag}
+	}
+	return nil
+}
+
+// BranchPolicy returns the release policy of the current branch. It returns a PolicyError if the current branch
+// doesn't match any of the branch policies. All branches are allowed without restrictions if the list is empty.
+func BranchPolicy(vc Repository, policies []config.Branch) (config.Branch, error) {
+	if len(policies) == 0 {
+		return config.Branch{}, nil
+	}
+
+	branch, err := vc.CurrentBranch()
+	if err != nil {
+		return config.Branch{}, fmt.Errorf("failed to detect the current branch: %w", err)
+	}
+
+	policy, ok := config.BranchPolicy(policies, branch)
+	if !ok {
+		names := make([]string, len(policies))
+		for i, p := range policies {
+			names[i] = p.Name
+		}
+		return config.Branch{}, &PolicyError{Err: fmt.Errorf(
+			"releases from the branch %v aren't allowed, allowed branches are %v", branch, names)}
+	}
+	return policy, nil
+}
+
+// FloatingAliases returns the changes of the alias tags, which are moved to the commit hash of the version. The alias
+// tags of the major and minor line are only moved if floating is set, the latest alias tag only if latest is set.
+func FloatingAliases(vc Repository, format version.Format, v version.Version, hash string, floating, latest bool) []transaction.Ref {
+	if !floating && !latest {
+		return nil
+	}
+
+	var aliases []transaction.Ref
+	for _, alias := range format.Aliases(v, Versions(vc, format, ""), latest) {
+		if alias != version.LatestAlias && !floating {
+			continue
+		}
+
+		ref := transaction.Ref{Name: "refs/tags/" + alias, Hash: hash}
+		if previous, err := vc.ResolveRevision(ref.Name); err == nil {
+			ref.Previous = previous
+		}
+		aliases = append(aliases, ref)
+	}
+	return aliases
+}
+
+// MaintenanceBranch returns the name of the maintenance branch of the release line of the final version, which is
+// named by the branch template. It returns an error for pre-releases and existing branches.
+func MaintenanceBranch(vc Repository, template string, v version.Version) (string, error) {
+	if v.IsReleaseCandidate() {
+		return "", fmt.Errorf("the pre-release %v can't cut a maintenance branch", v)
+	}
+
+	name := version.VersionLine(v, strings.Contains(template, "{minor}")).Branch(template)
+
+	existing, err := vc.Branches()
+	if err != nil {
+		return "", fmt.Errorf("failed to list the branches: %w", err)
+	}
+	for _, b := range existing {
+		if b == name {
+			return "", fmt.Errorf("the maintenance branch %v already exists", name)
+		}
+	}
+
+	return name, nil
+}
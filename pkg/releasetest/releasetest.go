@@ -0,0 +1,296 @@
+// Package releasetest builds git repositories for tests of release flows. A repository is a working tree with a bare
+// origin in a temporary directory, which is described by a list of steps:
+//
+//	repo := releasetest.New(t,
+//		releasetest.Commit("Add a"),
+//		releasetest.Tag("v1.0.0"),
+//		releasetest.Push(),
+//		releasetest.Remote(releasetest.Commit("Concurrent change")),
+//		releasetest.File("dirty.txt", "untracked"),
+//	)
+//	defer repo.Remove()
+//
+// The steps run the git command with a fixed identity, an isolated configuration and a clock, which starts at Epoch
+// and advances by a minute after each commit and annotated tag. Therefore the commit hashes are the same in each run
+// and may be part of golden files. The tests are skipped if git isn't installed.
+package releasetest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	// Name is the author and committer name of the commits.
+	Name = "Release Test"
+	// Email is the author and committer email of the commits.
+	Email = "release-test@example.com"
+	// Branch is the branch, which is checked out initially.
+	Branch = "master"
+)
+
+// Epoch is the time of the first commit.
+var Epoch = time.Date(2020, 4, 12, 8, 15, 0, 0, time.UTC)
+
+// Step changes the repository. A failing step fails the test.
+type Step func(r *Repository)
+
+// Repository is a working tree with a bare origin.
+type Repository struct {
+	// Dir is the temporary directory, which contains the working tree, the origin and the home directory.
+	Dir string
+	// Path is the working tree.
+	Path string
+	// Origin is the path of the bare repository, which is the remote origin of the working tree.
+	Origin string
+
+	t     testing.TB
+	clock *time.Time
+	// clones counts the clones of the origin, which are made by the Remote step.
+	clones *int
+}
+
+// New creates the repository with the origin and applies the steps. The working tree is empty and the Branch is
+// checked out. Remove deletes the repository after the test.
+func New(t testing.TB, steps ...Step) *Repository {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git isn't installed")
+	}
+
+	dir, err := ioutil.TempDir("", "releasetest")
+	if err != nil {
+		t.Fatalf("failed to create the temporary directory: %v", err)
+	}
+	// the paths of the repositories are compared with the output of git, which resolves symbolic links
+	if dir, err = filepath.EvalSymlinks(dir); err != nil {
+		t.Fatalf("failed to resolve the temporary directory: %v", err)
+	}
+	clock, clones := Epoch, 0
+	r := &Repository{
+		Dir:    dir,
+		Path:   filepath.Join(dir, "local"),
+		Origin: filepath.Join(dir, "origin.git"),
+		t:      t,
+		clock:  &clock,
+		clones: &clones,
+	}
+	if err := os.Mkdir(r.home(), 0755); err != nil {
+		t.Fatalf("failed to create the home directory: %v", err)
+	}
+
+	r.run(dir, "init", "--quiet", "--bare", r.Origin)
+	r.run(r.Origin, "symbolic-ref", "HEAD", "refs/heads/"+Branch)
+	r.run(dir, "init", "--quiet", r.Path)
+	r.Git("symbolic-ref", "HEAD", "refs/heads/"+Branch)
+	r.Git("config", "user.name", Name)
+	r.Git("config", "user.email", Email)
+	r.Git("remote", "add", "origin", r.Origin)
+
+	r.Apply(steps...)
+	return r
+}
+
+// Remove deletes the temporary directory of the repository.
+func (r *Repository) Remove() {
+	if err := os.RemoveAll(r.Dir); err != nil {
+		r.t.Errorf("failed to remove the repository: %v", err)
+	}
+}
+
+// Apply changes the repository with the steps in the given order.
+func (r *Repository) Apply(steps ...Step) {
+	r.t.Helper()
+	for _, step := range steps {
+		step(r)
+	}
+}
+
+// Env returns the environment of the git commands, which isolates them from the configuration of the user. Commands
+// under test should run with the same environment.
+func (r *Repository) Env() []string {
+	var env []string
+	for _, e := range os.Environ() {
+		if strings.HasPrefix(e, "GIT_") || strings.HasPrefix(e, "HOME=") || strings.HasPrefix(e, "XDG_CONFIG_HOME=") {
+			continue
+		}
+		env = append(env, e)
+	}
+
+	date := r.clock.Format(time.RFC3339)
+	return append(env,
+		"HOME="+r.home(),
+		"XDG_CONFIG_HOME="+filepath.Join(r.home(), ".config"),
+		"GIT_CONFIG_NOSYSTEM=1",
+		"GIT_TERMINAL_PROMPT=0",
+		"GIT_AUTHOR_NAME="+Name,
+		"GIT_AUTHOR_EMAIL="+Email,
+		"GIT_AUTHOR_DATE="+date,
+		"GIT_COMMITTER_NAME="+Name,
+		"GIT_COMMITTER_EMAIL="+Email,
+		"GIT_COMMITTER_DATE="+date,
+	)
+}
+
+// Git runs the git command in the working tree and returns the output without the trailing newline.
+func (r *Repository) Git(args ...string) string {
+	r.t.Helper()
+	return r.run(r.Path, args...)
+}
+
+// Hash returns the commit hash of the revision.
+func (r *Repository) Hash(revision string) string {
+	r.t.Helper()
+	return r.Git("rev-parse", "--verify", revision+"^{commit}")
+}
+
+// Refs returns the references of the repository at the path with the prefix, e.g. refs/tags, as lines of the object
+// hash and the reference name.
+func (r *Repository) Refs(path, prefix string) string {
+	r.t.Helper()
+	return r.run(path, "for-each-ref", "--format=%(objectname) %(refname)", prefix)
+}
+
+func (r *Repository) home() string {
+	return filepath.Join(r.Dir, "home")
+}
+
+func (r *Repository) run(dir string, args ...string) string {
+	r.t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = r.Env()
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		r.t.Fatalf("git %v failed: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimRight(string(out), "\n")
+}
+
+// tick advances the clock of the commits and tags.
+func (r *Repository) tick() {
+	*r.clock = r.clock.Add(time.Minute)
+}
+
+func (r *Repository) write(path, data string, mode os.FileMode) {
+	r.t.Helper()
+	path = filepath.Join(r.Path, filepath.FromSlash(path))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		r.t.Fatalf("failed to create the directory of %v: %v", path, err)
+	}
+	if err := ioutil.WriteFile(path, []byte(data), mode); err != nil {
+		r.t.Fatalf("failed to write %v: %v", path, err)
+	}
+	// the mode of an existing file isn't changed by WriteFile
+	if err := os.Chmod(path, mode); err != nil {
+		r.t.Fatalf("failed to change the mode of %v: %v", path, err)
+	}
+}
+
+// File writes the file at the slash-separated path of the working tree without staging it.
+func File(path, data string) Step {
+	return func(r *Repository) {
+		r.t.Helper()
+		r.write(path, data, 0644)
+	}
+}
+
+// Executable writes the executable file at the slash-separated path of the working tree without staging it.
+func Executable(path, data string) Step {
+	return func(r *Repository) {
+		r.t.Helper()
+		r.write(path, data, 0755)
+	}
+}
+
+// Delete removes the file at the slash-separated path of the working tree without staging the removal.
+func Delete(path string) Step {
+	return func(r *Repository) {
+		r.t.Helper()
+		if err := os.Remove(filepath.Join(r.Path, filepath.FromSlash(path))); err != nil {
+			r.t.Fatalf("failed to delete %v: %v", path, err)
+		}
+	}
+}
+
+// Stage adds the files to the index, e.g. to leave staged changes.
+func Stage(paths ...string) Step {
+	return func(r *Repository) {
+		r.t.Helper()
+		r.Git(append([]string{"add", "--all", "--"}, paths...)...)
+	}
+}
+
+// Commit stages all changes of the working tree and commits them with the message. The commit may be empty.
+func Commit(message string) Step {
+	return func(r *Repository) {
+		r.t.Helper()
+		r.Git("add", "--all")
+		r.Git("commit", "--quiet", "--allow-empty", "--message", message)
+		r.tick()
+	}
+}
+
+// Tag creates the lightweight tag of the HEAD.
+func Tag(name string) Step {
+	return func(r *Repository) {
+		r.t.Helper()
+		r.Git("tag", name)
+	}
+}
+
+// AnnotatedTag creates the annotated tag of the HEAD with the message.
+func AnnotatedTag(name, message string) Step {
+	return func(r *Repository) {
+		r.t.Helper()
+		r.Git("tag", "--annotate", "--message", message, name)
+		r.tick()
+	}
+}
+
+// NewBranch creates the branch at the HEAD and checks it out.
+func NewBranch(name string) Step {
+	return func(r *Repository) {
+		r.t.Helper()
+		r.Git("checkout", "--quiet", "-b", name)
+	}
+}
+
+// Checkout checks out the branch or the revision as detached HEAD.
+func Checkout(revision string) Step {
+	return func(r *Repository) {
+		r.t.Helper()
+		r.Git("checkout", "--quiet", revision)
+	}
+}
+
+// Push pushes all branches with their upstream branches and all tags to the origin.
+func Push() Step {
+	return func(r *Repository) {
+		r.t.Helper()
+		r.Git("push", "--quiet", "--set-upstream", "origin", "--all")
+		r.Git("push", "--quiet", "origin", "--tags")
+	}
+}
+
+// Remote applies the steps to a fresh clone of the origin and pushes the branch, which is checked out in the clone
+// afterwards, and all tags. It simulates the changes of another machine, e.g. remote commits let the working tree fall
+// behind and together with local commits the histories diverge.
+func Remote(steps ...Step) Step {
+	return func(r *Repository) {
+		r.t.Helper()
+		*r.clones++
+		clone := *r
+		clone.Path = filepath.Join(r.Dir, fmt.Sprintf("clone-%d", *r.clones))
+		r.run(r.Dir, "clone", "--quiet", r.Origin, clone.Path)
+		clone.Apply(steps...)
+		clone.Git("push", "--quiet", "origin", "HEAD")
+		clone.Git("push", "--quiet", "origin", "--tags")
+	}
+}
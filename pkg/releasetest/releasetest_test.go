@@ -0,0 +1,80 @@
+package releasetest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func history() []Step {
+	return []Step{
+		File("README.md", "# test\n"),
+		Commit("Initial commit"),
+		Tag("v1.0.0"),
+		Executable("build.sh", "#!/bin/sh\n"),
+		Commit("Add the build script"),
+		AnnotatedTag("v1.1.0", "Release v1.1.0"),
+		NewBranch("feature"),
+		Commit("Add a feature"),
+		Checkout(Branch),
+		Push(),
+	}
+}
+
+func TestNew(t *testing.T) {
+	repo := New(t, history()...)
+	defer repo.Remove()
+	other := New(t, history()...)
+	defer other.Remove()
+
+	assert.Equal(t, repo.Refs(repo.Path, "refs/tags"), other.Refs(other.Path, "refs/tags"), "the hashes are reproducible")
+	assert.Equal(t, repo.Refs(repo.Path, "refs/heads"), repo.Refs(repo.Origin, "refs/heads"))
+	assert.Equal(t, repo.Refs(repo.Path, "refs/tags"), repo.Refs(repo.Origin, "refs/tags"))
+	assert.Equal(t, Branch, repo.Git("rev-parse", "--abbrev-ref", "HEAD"))
+	assert.Equal(t, "origin/feature", repo.Git("rev-parse", "--abbrev-ref", "feature@{upstream}"))
+
+	assert.Equal(t, "commit", repo.Git("cat-file", "-t", "v1.0.0"))
+	assert.Equal(t, "tag", repo.Git("cat-file", "-t", "v1.1.0"))
+	assert.Equal(t, repo.Hash("HEAD"), repo.Hash("v1.1.0"))
+	assert.Equal(t, "100755 blob", repo.Git("ls-tree", "HEAD", "build.sh")[:11])
+	assert.Equal(t, Name+" <"+Email+"> 2020-04-12T08:16:00+00:00", repo.Git("log", "-1", "--format=%an <%ae> %cI", "v1.1.0"))
+}
+
+func TestWorktree(t *testing.T) {
+	repo := New(t, history()...)
+	defer repo.Remove()
+
+	repo.Apply(
+		File("README.md", "changed"),
+		Stage("README.md"),
+		Delete("build.sh"),
+		File("docs/new.md", "new"),
+	)
+	assert.Equal(t, "M  README.md\n D build.sh\n?? docs/", repo.Git("status", "--porcelain"))
+
+	data, err := ioutil.ReadFile(filepath.Join(repo.Path, "docs", "new.md"))
+	assert.NoError(t, err)
+	assert.Equal(t, "new", string(data))
+	_, err = os.Stat(filepath.Join(repo.Path, "build.sh"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestRemote(t *testing.T) {
+	repo := New(t, history()...)
+	defer repo.Remove()
+
+	repo.Apply(
+		Commit("Local change"),
+		Remote(Commit("Concurrent change"), Tag("v1.1.1")),
+	)
+	assert.Equal(t, "Local change", repo.Git("log", "-1", "--format=%s"))
+	assert.NotContains(t, repo.Refs(repo.Path, "refs/tags"), "v1.1.1", "the remote changes aren't fetched")
+
+	repo.Git("fetch", "--quiet", "--tags")
+	assert.Equal(t, "1\t1", repo.Git("rev-list", "--left-right", "--count", "HEAD...@{upstream}"), "diverged")
+	assert.Equal(t, repo.Hash("origin/master"), repo.Hash("v1.1.1"))
+	assert.Equal(t, "Concurrent change", repo.Git("log", "-1", "--format=%s", "v1.1.1"))
+}
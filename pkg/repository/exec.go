@@ -0,0 +1,520 @@
+package repository
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// GoGit is the backend of the go-git library.
+	GoGit = "go-git"
+	// Command is the backend of the git command.
+	Command = "exec"
+)
+
+// Backends lists all supported backends.
+var Backends = []string{GoGit, Command}
+
+// Exec is the version control client, which runs the git command and parses its porcelain output. Unlike the go-git
+// client it applies the whole git setup of the user like credential helpers, includeIf sections, signing and hooks.
+type Exec struct {
+	path   string
+	gitDir string
+	remote string
+}
+
+// NewExec creates the client of the working tree, which contains the path. It returns an error if git isn't
+// installed or the path isn't inside a working tree.
+func NewExec(path string) (*Exec, error) {
+	vc := &Exec{path: path}
+	out, err := vc.git(context.Background(), nil, "rev-parse", "--show-toplevel", "--absolute-git-dir")
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 2 {
+		return nil, fmt.Errorf("the path %v isn't inside a working tree", path)
+	}
+	vc.path, vc.gitDir = lines[0], lines[1]
+	return vc, nil
+}
+
+// git runs the git command in the working tree and returns its output. The error contains the message of git.
+func (vc *Exec) git(ctx context.Context, stdin io.Reader, args ...string) (string, error) {
+	return vc.gitEnv(ctx, stdin, nil, args...)
+}
+
+// gitEnv runs the git command with the additional environment variables.
+func (vc *Exec) gitEnv(ctx context.Context, stdin io.Reader, env []string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = vc.path
+	cmd.Stdin = stdin
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if env != nil {
+		cmd.Env = append(os.Environ(), env...)
+	}
+
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", fmt.Errorf("git %v: %v", args[0], message)
+		}
+		return "", fmt.Errorf("git %v: %w", args[0], err)
+	}
+	return stdout.String(), nil
+}
+
+// lines returns the non-empty lines of the output.
+func lines(out string) []string {
+	var result []string
+	for _, line := range strings.Split(out, "\n") {
+		if line != "" {
+			result = append(result, line)
+		}
+	}
+	return result
+}
+
+// SetRemote sets the name of the remote which is fetched and pushed. The default remote is origin.
+func (vc *Exec) SetRemote(name string) {
+	vc.remote = name
+}
+
+func (vc *Exec) remoteName() string {
+	if vc.remote == "" {
+		return "origin"
+	}
+	return vc.remote
+}
+
+// Path returns the root directory of the working tree.
+func (vc *Exec) Path() string {
+	return vc.path
+}
+
+// GitDir returns the git directory of the repository, which stores the objects and references.
+func (vc *Exec) GitDir() string {
+	return vc.gitDir
+}
+
+// LatestCommitHash returns the hash of the HEAD commit. In case of an error the result is empty.
+func (vc *Exec) LatestCommitHash() string {
+	hash, err := vc.ResolveRevision("HEAD")
+	if err != nil {
+		return ""
+	}
+	return hash
+}
+
+// ExistsTag returns the existence of the tag with the full reference name, e.g. refs/tags/v1.2.3.
+func (vc *Exec) ExistsTag(version string) (bool, error) {
+	out, err := vc.git(context.Background(), nil, "for-each-ref", "--format=%(refname)", version)
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(out) == version, nil
+}
+
+// Tags lists all existing tags of the repository in the notation of git show-ref.
+func (vc *Exec) Tags() []string {
+	return vc.tags()
+}
+
+// tags lists the tags in the notation of git show-ref, which are optionally filtered by the arguments of git
+// for-each-ref.
+func (vc *Exec) tags(args ...string) []string {
+	args = append([]string{"for-each-ref", "--format=%(objectname) %(refname)"}, args...)
+	out, err := vc.git(context.Background(), nil, append(args, "refs/tags")...)
+	if err != nil {
+		return nil
+	}
+	return append(make([]string, 0), lines(out)...)
+}
+
+// BranchTags lists all existing tags associated to commits of the branch, which is merged from the upstream branch.
+func (vc *Exec) BranchTags(branchName string) []string {
+	out, err := vc.git(context.Background(), nil, "config", "--get", "branch."+branchName+".merge")
+	if err != nil {
+		return nil
+	}
+	hash, err := vc.ResolveRevision(strings.TrimSpace(out))
+	if err != nil {
+		return nil
+	}
+	return vc.tags("--merged=" + hash)
+}
+
+// ReachableTags lists all existing tags associated to commits reachable from the given revision.
+func (vc *Exec) ReachableTags(revision string) []string {
+	hash, err := vc.ResolveRevision(revision)
+	if err != nil {
+		return nil
+	}
+	return vc.tags("--merged=" + hash)
+}
+
+// Commits lists all commits reachable from the revision to but not from the revision from, newest first. All commits
+// reachable from the revision to are listed if from is empty.
+func (vc *Exec) Commits(from, to string) ([]Commit, error) {
+	toHash, err := vc.ResolveRevision(to)
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve revision %v: %v", to, err)
+	}
+	args := []string{"log", "-z", "--format=%H%n%an%n%cI%n%B", toHash}
+	if from != "" {
+		fromHash, err := vc.ResolveRevision(from)
+		if err != nil {
+			return nil, fmt.Errorf("could not resolve revision %v: %v", from, err)
+		}
+		args = append(args, "^"+fromHash)
+	}
+
+	out, err := vc.git(context.Background(), nil, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var commits = make([]Commit, 0)
+	for _, entry := range strings.Split(out, "\x00") {
+		fields := strings.SplitN(entry, "\n", 4)
+		if len(fields) < 4 {
+			continue
+		}
+		when, err := time.Parse(time.RFC3339, fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid commit time of %v: %w", fields[0], err)
+		}
+		commits = append(commits, Commit{
+			Hash:    fields[0],
+			Author:  fields[1],
+			When:    when,
+			Message: fields[3],
+		})
+	}
+	return commits, nil
+}
+
+// IsSafe returns an error if the working tree has uncommitted, untracked or staged changes or the current branch is
+// behind its upstream branch.
+func (vc *Exec) IsSafe(ctx context.Context) error {
+	if hasUncommittedChanges, err := vc.HasUncommittedChanges(); err != nil {
+		return err
+	} else if hasUncommittedChanges {
+		return fmt.Errorf("your client has uncommitted changes.")
+	}
+
+	if vc.HasStagedChanges() {
+		return fmt.Errorf("your client has staged changes, which aren't committed.")
+	}
+
+	if isBehind, err := vc.IsBehind(ctx); err != nil {
+		return fmt.Errorf("could not determine remote status: %v.", err)
+	} else if isBehind {
+		return fmt.Errorf("your branch is behind the remote. Please pull.")
+	}
+
+	return nil
+}
+
+// HasUncommittedChanges checks the working tree for modified and untracked files.
+func (vc *Exec) HasUncommittedChanges() (bool, error) {
+	out, err := vc.git(context.Background(), nil, "status", "--porcelain")
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(out) != "", nil
+}
+
+// HasStagedChanges checks the index for changes, which aren't committed.
+func (vc *Exec) HasStagedChanges() bool {
+	_, err := vc.git(context.Background(), nil, "diff", "--cached", "--quiet")
+	return err != nil
+}
+
+// IsBehind fetches the remote and reports whether the branch of the HEAD on the remote has commits, which the HEAD
+// doesn't contain. The branch on the remote is the upstream branch if it's tracked from the remote and otherwise the
+// branch with the same name, e.g. in CI checkouts without upstream branch. A detached HEAD and branches, which don't
+// exist on the remote yet, are never behind.
+func (vc *Exec) IsBehind(ctx context.Context) (bool, error) {
+	if _, err := vc.git(ctx, nil, "fetch", "--quiet", vc.remoteName()); err != nil {
+		return false, err
+	}
+
+	branch, err := vc.git(ctx, nil, "symbolic-ref", "--quiet", "--short", "HEAD")
+	if err != nil {
+		return false, nil
+	}
+	branch = strings.TrimSpace(branch)
+	upstream := "refs/remotes/" + vc.remoteName() + "/" + branch
+	if remote, err := vc.git(ctx, nil, "config", "branch."+branch+".remote"); err == nil &&
+		strings.TrimSpace(remote) == vc.remoteName() {
+		upstream = "@{upstream}"
+	}
+
+	if _, err := vc.git(ctx, nil, "rev-parse", "--verify", "--quiet", upstream); err != nil {
+		return false, nil
+	}
+	out, err := vc.git(ctx, nil, "rev-list", "--count", "HEAD.."+upstream)
+	if err != nil {
+		return false, err
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(out))
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// CreateTag creates a lightweight tag of the HEAD commit.
+func (vc *Exec) CreateTag(tag string) error {
+	if _, err := vc.git(context.Background(), nil, "tag", tag, "HEAD"); err != nil {
+		return fmt.Errorf("could not create a new tag: %v", err)
+	}
+	return nil
+}
+
+// ResolveRevision returns the commit hash of the given revision, e.g. a tag name or a commit hash.
+func (vc *Exec) ResolveRevision(revision string) (string, error) {
+	out, err := vc.git(context.Background(), nil, "rev-parse", "--verify", "--quiet", revision+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("reference not found: %v", revision)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// SetReference creates or moves the reference with the full name, e.g. refs/tags/v1.2.3, to the object hash.
+func (vc *Exec) SetReference(name, hash string) error {
+	_, err := vc.git(context.Background(), nil, "update-ref", name, hash)
+	return err
+}
+
+// RemoveReference deletes the reference with the full name, e.g. refs/tags/v1.2.3.
+func (vc *Exec) RemoveReference(name string) error {
+	_, err := vc.git(context.Background(), nil, "update-ref", "-d", name)
+	return err
+}
+
+// RemoteReferences lists the references of the remote with their hashes.
+func (vc *Exec) RemoteReferences(ctx context.Context) (map[string]string, error) {
+	out, err := vc.git(ctx, nil, "ls-remote", vc.remoteName())
+	if err != nil {
+		return nil, err
+	}
+
+	var refs = make(map[string]string)
+	for _, line := range lines(out) {
+		fields := strings.Fields(line)
+		if len(fields) != 2 || strings.HasSuffix(fields[1], "^{}") {
+			continue
+		}
+		refs[fields[1]] = fields[0]
+	}
+	return refs, nil
+}
+
+// FetchTags fetches all tags of the remote.
+func (vc *Exec) FetchTags(ctx context.Context) error {
+	_, err := vc.git(ctx, nil, "fetch", "--quiet", vc.remoteName(), "+refs/tags/*:refs/tags/*")
+	return err
+}
+
+// CreateOrphanCommit creates a commit without parents and files, which isn't referenced by any branch. It returns the
+// hash of the commit.
+func (vc *Exec) CreateOrphanCommit(message string) (string, error) {
+	tree, err := vc.git(context.Background(), strings.NewReader(""), "mktree")
+	if err != nil {
+		return "", err
+	}
+
+	out, err := vc.gitEnv(context.Background(), nil, []string{
+		"GIT_AUTHOR_NAME=release", "GIT_AUTHOR_EMAIL=release@localhost",
+		"GIT_COMMITTER_NAME=release", "GIT_COMMITTER_EMAIL=release@localhost",
+	}, "commit-tree", "--no-gpg-sign", "-m", message, strings.TrimSpace(tree))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// CreateTagObject creates the object of an annotated tag of the commit hash, which isn't referenced by any tag yet. It
+// returns the hash of the tag object. The tag object is signed if the git configuration enables tag.gpgSign.
+func (vc *Exec) CreateTagObject(name, hash, message string) (string, error) {
+	// git creates tag objects only with their reference, which is removed afterwards
+	ref := "refs/tags/" + name
+	if _, err := vc.git(context.Background(), strings.NewReader(message), "tag", "--annotate",
+		"--cleanup=whitespace", "--file=-", name, hash); err != nil {
+		return "", err
+	}
+	out, err := vc.git(context.Background(), nil, "rev-parse", "--verify", ref)
+	if err != nil {
+		return "", err
+	}
+	tag := strings.TrimSpace(out)
+	if _, err := vc.git(context.Background(), nil, "update-ref", "-d", ref, tag); err != nil {
+		return "", err
+	}
+	return tag, nil
+}
+
+// DeleteTag deletes a local tag.
+func (vc *Exec) DeleteTag(tag string) error {
+	return vc.RemoveReference("refs/tags/" + tag)
+}
+
+// Branches lists the sorted names of the local branches and of the branches of the remote.
+func (vc *Exec) Branches() ([]string, error) {
+	remotePrefix := fmt.Sprintf("refs/remotes/%v/", vc.remoteName())
+	out, err := vc.git(context.Background(), nil, "for-each-ref", "--format=%(refname)", "refs/heads",
+		remotePrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	names := map[string]bool{}
+	for _, name := range lines(out) {
+		switch {
+		case strings.HasPrefix(name, "refs/heads/"):
+			names[strings.TrimPrefix(name, "refs/heads/")] = true
+		case name != remotePrefix+"HEAD":
+			names[strings.TrimPrefix(name, remotePrefix)] = true
+		}
+	}
+
+	branches := make([]string, 0, len(names))
+	for name := range names {
+		branches = append(branches, name)
+	}
+	sort.Strings(branches)
+
+	return branches, nil
+}
+
+// RemoteURL returns the URL of the remote.
+func (vc *Exec) RemoteURL() (string, error) {
+	out, err := vc.git(context.Background(), nil, "config", "--get", "remote."+vc.remoteName()+".url")
+	if err != nil {
+		return "", fmt.Errorf("remote not found: %v", vc.remoteName())
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// CurrentBranch returns the name of the checked out branch.
+func (vc *Exec) CurrentBranch() (string, error) {
+	out, err := vc.git(context.Background(), nil, "symbolic-ref", "--quiet", "--short", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("the HEAD is detached")
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// Commit adds the given files to the index and commits them. The commit runs the git hooks and is signed if the git
+// configuration enables commit.gpgSign.
+func (vc *Exec) Commit(message string, files ...string) (string, error) {
+	if len(files) > 0 {
+		args := append([]string{"add", "--all", "--"}, files...)
+		if _, err := vc.git(context.Background(), nil, args...); err != nil {
+			return "", fmt.Errorf("could not add the files to the index: %v", err)
+		}
+	}
+
+	if _, err := vc.git(context.Background(), strings.NewReader(message), "commit", "--quiet",
+		"--cleanup=whitespace", "--file=-"); err != nil {
+		return "", fmt.Errorf("could not commit: %v", err)
+	}
+
+	return vc.ResolveRevision("HEAD")
+}
+
+// Push pushes the ref specs to the remote. Without ref specs all tags are pushed. The push is atomic, so a rejected
+// branch doesn't leave the pushed tag of a failed release on the remote. The go-git backend has no atomic push, it
+// only refuses the whole push if it detects a non-fast-forward update before sending the references.
+func (vc *Exec) Push(ctx context.Context, refSpecs ...string) error {
+	if len(refSpecs) == 0 {
+		refSpecs = []string{"refs/tags/*:refs/tags/*"}
+	}
+
+	args := append([]string{"push", "--quiet", "--atomic", vc.remoteName()}, refSpecs...)
+	_, err := vc.git(ctx, nil, args...)
+	return err
+}
+
+// Files lists all files of the given revision, e.g. a tag name or a commit hash, read from the local object store.
+func (vc *Exec) Files(revision string) ([]File, error) {
+	hash, err := vc.ResolveRevision(revision)
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve revision %v: %v", revision, err)
+	}
+
+	out, err := vc.git(context.Background(), nil, "ls-tree", "-r", "-z", "--full-tree", hash)
+	if err != nil {
+		return nil, err
+	}
+
+	var files = make([]File, 0)
+	var objects []string
+	for _, entry := range strings.Split(out, "\x00") {
+		// <mode> SP <type> SP <object> TAB <path>
+		tab := strings.IndexByte(entry, '\t')
+		if tab < 0 {
+			continue
+		}
+		fields := strings.Fields(entry[:tab])
+		if len(fields) != 3 || fields[1] != "blob" {
+			continue
+		}
+
+		mode := os.FileMode(0644)
+		switch fields[0] {
+		case "100755":
+			mode = 0755
+		case "120000":
+			mode = os.ModePerm | os.ModeSymlink
+		}
+		files = append(files, File{Path: entry[tab+1:], Mode: mode})
+		objects = append(objects, fields[2])
+	}
+	if len(files) == 0 {
+		return files, nil
+	}
+
+	out, err = vc.git(context.Background(), strings.NewReader(strings.Join(objects, "\n")+"\n"), "cat-file",
+		"--batch")
+	if err != nil {
+		return nil, err
+	}
+	r := bufio.NewReader(strings.NewReader(out))
+	for i := range files {
+		// <object> SP <type> SP <size> LF <contents> LF
+		header, err := r.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("invalid object of %v: %w", files[i].Path, err)
+		}
+		fields := strings.Fields(header)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("invalid object of %v: %v", files[i].Path, strings.TrimSpace(header))
+		}
+		size, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid object of %v: %w", files[i].Path, err)
+		}
+		files[i].Data = make([]byte, size)
+		if _, err := io.ReadFull(r, files[i].Data); err != nil {
+			return nil, fmt.Errorf("invalid object of %v: %w", files[i].Path, err)
+		}
+		if _, err := r.Discard(1); err != nil {
+			return nil, fmt.Errorf("invalid object of %v: %w", files[i].Path, err)
+		}
+	}
+
+	return files, nil
+}
@@ -3,18 +3,27 @@ package repository
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
+	format "github.com/go-git/go-git/v5/plumbing/format/config"
 	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/storage/filesystem"
 )
 
 // Git is the version control client for git
 type Git struct {
 	client *git.Repository
+	path   string
+	remote string
 }
 
 // New creates an new instance of the git client
@@ -25,9 +34,27 @@ func New(path string) (*Git, error) {
 	}
 	return &Git{
 		client: repo,
+		path:   path,
 	}, nil
 }
 
+// SetRemote sets the name of the remote which is fetched and pushed. The default remote is origin.
+func (vc *Git) SetRemote(name string) {
+	vc.remote = name
+}
+
+func (vc *Git) remoteName() string {
+	if vc.remote == "" {
+		return git.DefaultRemoteName
+	}
+	return vc.remote
+}
+
+// Path returns the root directory of the git repo.
+func (vc *Git) Path() string {
+	return vc.path
+}
+
 // LatestCommitHash returns the latest commit hash of the git repo. In case of an error the result is empty.
 func (vc *Git) LatestCommitHash() string {
 	headRef, err := vc.client.Head()
@@ -90,9 +117,23 @@ func (vc *Git) BranchTags(branchName string) []string {
 		return nil
 	}
 
-	// get all commit hashes of the given branch
+	return vc.reachableTags(ref.Hash())
+}
+
+// ReachableTags lists all existing tags associated to commits reachable from the given revision.
+func (vc *Git) ReachableTags(revision string) []string {
+	hash, err := vc.client.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return nil
+	}
+
+	return vc.reachableTags(*hash)
+}
+
+func (vc *Git) reachableTags(from plumbing.Hash) []string {
+	// get all commit hashes reachable from the given commit
 	logs, err := vc.client.Log(&git.LogOptions{
-		From: ref.Hash(),
+		From: from,
 	})
 	if err != nil {
 		return nil
@@ -125,7 +166,7 @@ func (vc *Git) BranchTags(branchName string) []string {
 		return nil
 	}
 
-	// only return tags whose associated commit hash belongs to the master branch
+	// only return tags whose associated commit hash is reachable
 	var branchTags = make([]string, 0)
 	for tag, commit := range tagsWithCommits {
 		if _, ok := branchCommits[commit]; ok {
@@ -134,7 +175,70 @@ func (vc *Git) BranchTags(branchName string) []string {
 	}
 
 	return branchTags
+}
 
+// Commit is a commit of the repository history.
+type Commit struct {
+	// Hash is the full commit hash.
+	Hash string
+	// Message is the full commit message.
+	Message string
+	// Author is the name of the commit author.
+	Author string
+	// When is the commit time of the committer.
+	When time.Time
+}
+
+// Commits lists all commits reachable from the revision to but not from the revision from, newest first. All commits
+// reachable from the revision to are listed if from is empty.
+func (vc *Git) Commits(from, to string) ([]Commit, error) {
+	toHash, err := vc.client.ResolveRevision(plumbing.Revision(to))
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve revision %v: %v", to, err)
+	}
+
+	var excluded = make(map[plumbing.Hash]bool)
+	if from != "" {
+		fromHash, err := vc.client.ResolveRevision(plumbing.Revision(from))
+		if err != nil {
+			return nil, fmt.Errorf("could not resolve revision %v: %v", from, err)
+		}
+
+		logs, err := vc.client.Log(&git.LogOptions{From: *fromHash})
+		if err != nil {
+			return nil, err
+		}
+		defer logs.Close()
+		if err := logs.ForEach(func(commit *object.Commit) error {
+			excluded[commit.Hash] = true
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	logs, err := vc.client.Log(&git.LogOptions{From: *toHash})
+	if err != nil {
+		return nil, err
+	}
+	defer logs.Close()
+
+	var commits = make([]Commit, 0)
+	if err := logs.ForEach(func(commit *object.Commit) error {
+		if !excluded[commit.Hash] {
+			commits = append(commits, Commit{
+				Hash:    commit.Hash.String(),
+				Message: commit.Message,
+				Author:  commit.Author.Name,
+				When:    commit.Committer.When,
+			})
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return commits, nil
 }
 
 // IsSafe validate the state of the git repo and returns an error if the repo is unsafe like include uncommitted files
@@ -151,11 +255,8 @@ func (vc *Git) IsSafe(ctx context.Context) error {
 	}
 
 	if isBehind, err := vc.IsBehind(ctx); err != nil {
-		if strings.Contains(err.Error(), "already up-to-date") {
-			return nil
-		}
 		return fmt.Errorf("could not determine remote status: %v.", err)
-	} else if !isBehind {
+	} else if isBehind {
 		return fmt.Errorf("your branch is behind the remote. Please pull.")
 	}
 
@@ -184,13 +285,44 @@ func (vc *Git) HasStagedChanges() bool {
 	return false
 }
 
-// IsBehind checks the local repo with the origin and validate the state of the git repo
+// IsBehind fetches the remote and reports whether the branch of the HEAD on the remote has commits, which the HEAD
+// doesn't contain. The branch on the remote is the upstream branch if it's tracked from the remote and otherwise the
+// branch with the same name, e.g. in CI checkouts without upstream branch. A detached HEAD and branches, which don't
+// exist on the remote yet, are never behind.
 func (vc *Git) IsBehind(ctx context.Context) (bool, error) {
-	if err := vc.client.FetchContext(ctx, &git.FetchOptions{}); err != nil {
+	if err := vc.client.FetchContext(ctx, &git.FetchOptions{
+		RemoteName: vc.remoteName(),
+	}); err != nil && err != git.NoErrAlreadyUpToDate {
 		return false, err
 	}
 
-	return true, nil
+	head, err := vc.client.Head()
+	if err != nil {
+		return false, err
+	}
+	if !head.Name().IsBranch() {
+		return false, nil
+	}
+	name := plumbing.NewRemoteReferenceName(vc.remoteName(), head.Name().Short())
+	if branch, err := vc.client.Branch(head.Name().Short()); err == nil && branch.Remote == vc.remoteName() &&
+		branch.Merge != "" {
+		name = plumbing.NewRemoteReferenceName(branch.Remote, branch.Merge.Short())
+	}
+	upstream, err := vc.client.Reference(name, true)
+	if err != nil || upstream.Hash() == head.Hash() {
+		return false, nil
+	}
+
+	headCommit, err := vc.client.CommitObject(head.Hash())
+	if err != nil {
+		return false, err
+	}
+	upstreamCommit, err := vc.client.CommitObject(upstream.Hash())
+	if err != nil {
+		return false, err
+	}
+	contained, err := upstreamCommit.IsAncestor(headCommit)
+	return !contained, err
 }
 
 // CreateTag creates a local git tag.
@@ -202,6 +334,132 @@ func (vc *Git) CreateTag(tag string) error {
 	return nil
 }
 
+// ResolveRevision returns the commit hash of the given revision, e.g. a tag name or a commit hash.
+func (vc *Git) ResolveRevision(revision string) (string, error) {
+	hash, err := vc.client.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return "", err
+	}
+
+	return hash.String(), nil
+}
+
+// SetReference creates or moves the reference with the full name, e.g. refs/tags/v1.2.3, to the commit hash.
+func (vc *Git) SetReference(name, hash string) error {
+	return vc.client.Storer.SetReference(
+		plumbing.NewHashReference(plumbing.ReferenceName(name), plumbing.NewHash(hash)),
+	)
+}
+
+// RemoveReference deletes the reference with the full name, e.g. refs/tags/v1.2.3.
+func (vc *Git) RemoveReference(name string) error {
+	return vc.client.Storer.RemoveReference(plumbing.ReferenceName(name))
+}
+
+// RemoteReferences lists the references of the remote with their commit hashes. go-git can't cancel a running
+// listing, so the context is only checked before.
+func (vc *Git) RemoteReferences(ctx context.Context) (map[string]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	remote, err := vc.client.Remote(vc.remoteName())
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := remote.List(&git.ListOptions{})
+	if err == transport.ErrEmptyRemoteRepository {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var refs = make(map[string]string, len(list))
+	for _, ref := range list {
+		refs[ref.Name().String()] = ref.Hash().String()
+	}
+	return refs, nil
+}
+
+// FetchTags fetches all tags of the remote.
+func (vc *Git) FetchTags(ctx context.Context) error {
+	err := vc.client.FetchContext(ctx, &git.FetchOptions{
+		RemoteName: vc.remoteName(),
+		RefSpecs:   []config.RefSpec{"+refs/tags/*:refs/tags/*"},
+		Tags:       git.AllTags,
+	})
+	if err == git.NoErrAlreadyUpToDate {
+		return nil
+	}
+	return err
+}
+
+// CreateOrphanCommit creates a commit without parents and files, which isn't referenced by any branch. It returns the
+// hash of the commit.
+func (vc *Git) CreateOrphanCommit(message string) (string, error) {
+	tree := vc.client.Storer.NewEncodedObject()
+	if err := (&object.Tree{}).Encode(tree); err != nil {
+		return "", err
+	}
+	treeHash, err := vc.client.Storer.SetEncodedObject(tree)
+	if err != nil {
+		return "", err
+	}
+
+	signature := object.Signature{Name: "release", Email: "release@localhost", When: time.Now()}
+	commit := vc.client.Storer.NewEncodedObject()
+	if err := (&object.Commit{
+		Author:    signature,
+		Committer: signature,
+		Message:   message,
+		TreeHash:  treeHash,
+	}).Encode(commit); err != nil {
+		return "", err
+	}
+	hash, err := vc.client.Storer.SetEncodedObject(commit)
+	if err != nil {
+		return "", err
+	}
+
+	return hash.String(), nil
+}
+
+// CreateTagObject creates the object of an annotated tag of the commit hash, which isn't referenced by any tag yet. It
+// returns the hash of the tag object.
+func (vc *Git) CreateTagObject(name, hash, message string) (string, error) {
+	tagger, err := vc.signature()
+	if err != nil {
+		return "", err
+	}
+
+	tag := vc.client.Storer.NewEncodedObject()
+	if err := (&object.Tag{
+		Name:       name,
+		Tagger:     *tagger,
+		Message:    strings.TrimSpace(message) + "\n",
+		TargetType: plumbing.CommitObject,
+		Target:     plumbing.NewHash(hash),
+	}).Encode(tag); err != nil {
+		return "", err
+	}
+	tagHash, err := vc.client.Storer.SetEncodedObject(tag)
+	if err != nil {
+		return "", err
+	}
+
+	return tagHash.String(), nil
+}
+
+// GitDir returns the git directory of the repository, which stores the objects and references.
+func (vc *Git) GitDir() string {
+	if storage, ok := vc.client.Storer.(*filesystem.Storage); ok {
+		return storage.Filesystem().Root()
+	}
+	return filepath.Join(vc.path, git.GitDirName)
+}
+
 // DeleteTag deletes a local git tag.
 func (vc *Git) DeleteTag(tag string) error {
 	return vc.client.Storer.RemoveReference(
@@ -209,59 +467,265 @@ func (vc *Git) DeleteTag(tag string) error {
 	)
 }
 
-// Push pushes the local repo state to the origin.
-func (vc *Git) Push(ctx context.Context) error {
-	return vc.client.PushContext(ctx, &git.PushOptions{
-		RefSpecs: []config.RefSpec{"refs/tags/*:refs/tags/*"},
+// Branches lists the sorted names of the local branches and of the branches of the remote.
+func (vc *Git) Branches() ([]string, error) {
+	refs, err := vc.client.References()
+	if err != nil {
+		return nil, err
+	}
+
+	remotePrefix := fmt.Sprintf("refs/remotes/%v/", vc.remoteName())
+	names := map[string]bool{}
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		switch name := ref.Name().String(); {
+		case ref.Name().IsBranch():
+			names[ref.Name().Short()] = true
+		case strings.HasPrefix(name, remotePrefix) && name != remotePrefix+"HEAD":
+			names[strings.TrimPrefix(name, remotePrefix)] = true
+		}
+		return nil
 	})
-}
+	if err != nil {
+		return nil, err
+	}
 
-// NoOpRepository is the implementation of an no-operation client.
-type NoOpRepository struct{}
+	branches := make([]string, 0, len(names))
+	for name := range names {
+		branches = append(branches, name)
+	}
+	sort.Strings(branches)
 
-// NewNoOp creates an new instance of the No-Operation object
-func NewNoOp() *NoOpRepository {
-	return &NoOpRepository{}
+	return branches, nil
 }
 
-// LatestCommitHash does nothing.
-func (noop *NoOpRepository) LatestCommitHash() string {
-	return ""
+// RemoteURL returns the URL of the origin.
+func (vc *Git) RemoteURL() (string, error) {
+	remote, err := vc.client.Remote(vc.remoteName())
+	if err != nil {
+		return "", err
+	}
+	if urls := remote.Config().URLs; len(urls) > 0 {
+		return urls[0], nil
+	}
+	return "", fmt.Errorf("the remote %v has no URL", vc.remoteName())
 }
 
-// ExistsTag does nothing.
-func (noop *NoOpRepository) ExistsTag(version string) (bool, error) {
-	return true, nil
+// CurrentBranch returns the name of the checked out branch.
+func (vc *Git) CurrentBranch() (string, error) {
+	headRef, err := vc.client.Head()
+	if err != nil {
+		return "", err
+	}
+	if !headRef.Name().IsBranch() {
+		return "", fmt.Errorf("the HEAD is detached")
+	}
+
+	return headRef.Name().Short(), nil
 }
 
-// Tags does nothing
-func (noop *NoOpRepository) Tags() []string {
-	currentPath, err := os.Getwd()
+// Commit adds the given files to the index and commits them with the configured git user as author.
+func (vc *Git) Commit(message string, files ...string) (string, error) {
+	w, err := vc.client.Worktree()
+	if err != nil {
+		return "", err
+	}
+
+	for _, f := range files {
+		if _, err := w.Add(f); err != nil {
+			return "", fmt.Errorf("could not add %v to the index: %v", f, err)
+		}
+	}
+
+	author, err := vc.signature()
 	if err != nil {
-		return make([]string, 0)
+		return "", err
 	}
 
-	repository, err := New(currentPath)
+	hash, err := w.Commit(message, &git.CommitOptions{
+		Author: author,
+	})
 	if err != nil {
-		return make([]string, 0)
+		return "", fmt.Errorf("could not commit: %v", err)
 	}
 
-	return repository.Tags()
+	return hash.String(), nil
 }
 
-// BranchTags does nothing
-func (noop *NoOpRepository) BranchTags(branchName string) []string {
-	currentPath, err := os.Getwd()
+// signature returns the configured git user of the repository or the global git configuration.
+func (vc *Git) signature() (*object.Signature, error) {
+	var name, email = os.Getenv("GIT_AUTHOR_NAME"), os.Getenv("GIT_AUTHOR_EMAIL")
+
+	var sections []*format.Section
+	if cfg, err := vc.client.Config(); err == nil && cfg.Raw != nil {
+		sections = append(sections, cfg.Raw.Section("user"))
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		if f, err := os.Open(filepath.Join(home, ".gitconfig")); err == nil {
+			global := format.New()
+			if err := format.NewDecoder(f).Decode(global); err == nil {
+				sections = append(sections, global.Section("user"))
+			}
+			f.Close()
+		}
+	}
+
+	for _, section := range sections {
+		if name == "" {
+			name = section.Option("name")
+		}
+		if email == "" {
+			email = section.Option("email")
+		}
+	}
+
+	if name == "" || email == "" {
+		return nil, fmt.Errorf("the git user name and email aren't configured")
+	}
+
+	return &object.Signature{
+		Name:  name,
+		Email: email,
+		When:  time.Now(),
+	}, nil
+}
+
+// Push pushes the local repo state to the origin. Without ref specs all tags are pushed. The push isn't atomic, a
+// remote, which rejects one reference, may accept the others. Only non-fast-forward updates are detected before and
+// refuse the whole push.
+func (vc *Git) Push(ctx context.Context, refSpecs ...string) error {
+	if len(refSpecs) == 0 {
+		refSpecs = []string{"refs/tags/*:refs/tags/*"}
+	}
+
+	var specs = make([]config.RefSpec, 0, len(refSpecs))
+	for _, spec := range refSpecs {
+		specs = append(specs, config.RefSpec(spec))
+	}
+
+	return vc.client.PushContext(ctx, &git.PushOptions{
+		RemoteName: vc.remoteName(),
+		RefSpecs:   specs,
+	})
+}
+
+// File is a regular file of a repository revision.
+type File struct {
+	// Path is the slash separated path of the file relative to the repository root.
+	Path string
+	// Mode is the file mode recorded in the tree of the revision.
+	Mode os.FileMode
+	// Data is the content of the file.
+	Data []byte
+}
+
+// Files lists all files of the given revision, e.g. a tag name or a commit hash, read from the local object store.
+func (vc *Git) Files(revision string) ([]File, error) {
+	hash, err := vc.client.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve revision %v: %v", revision, err)
+	}
+
+	commit, err := vc.client.CommitObject(*hash)
 	if err != nil {
-		return make([]string, 0)
+		return nil, err
 	}
 
-	repository, err := New(currentPath)
+	fIter, err := commit.Files()
 	if err != nil {
-		return make([]string, 0)
+		return nil, err
+	}
+	defer fIter.Close()
+
+	var files = make([]File, 0)
+	if err := fIter.ForEach(func(f *object.File) error {
+		mode, err := f.Mode.ToOSFileMode()
+		if err != nil {
+			return err
+		}
+
+		r, err := f.Reader()
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+
+		data, err := ioutil.ReadAll(r)
+		if err != nil {
+			return err
+		}
+
+		files = append(files, File{
+			Path: f.Name,
+			Mode: mode,
+			Data: data,
+		})
+		return nil
+	}); err != nil {
+		return nil, err
 	}
 
-	return repository.BranchTags(branchName)
+	return files, nil
+}
+
+// Reader reads the state of a repository, which doesn't change anything.
+type Reader interface {
+	LatestCommitHash() string
+	Tags() []string
+	BranchTags(branchName string) []string
+	Files(revision string) ([]File, error)
+	ReachableTags(revision string) []string
+	Commits(from, to string) ([]Commit, error)
+	ResolveRevision(revision string) (string, error)
+	RemoteReferences(ctx context.Context) (map[string]string, error)
+	CurrentBranch() (string, error)
+	Branches() ([]string, error)
+	RemoteURL() (string, error)
+}
+
+// NoOpRepository is the implementation of an no-operation client. It reads the wrapped client of either backend and
+// drops all changes.
+type NoOpRepository struct {
+	repo Reader
+}
+
+// NewNoOp creates an new instance of the No-Operation object, which reads the given client.
+func NewNoOp(repo Reader) *NoOpRepository {
+	return &NoOpRepository{repo: repo}
+}
+
+// LatestCommitHash returns the latest commit hash, because reading doesn't change anything.
+func (noop *NoOpRepository) LatestCommitHash() string {
+	return noop.repo.LatestCommitHash()
+}
+
+// ExistsTag does nothing.
+func (noop *NoOpRepository) ExistsTag(version string) (bool, error) {
+	return true, nil
+}
+
+// Tags lists all tags, because reading doesn't change anything.
+func (noop *NoOpRepository) Tags() []string {
+	return noop.repo.Tags()
+}
+
+// BranchTags lists the tags of the branch, because reading doesn't change anything.
+func (noop *NoOpRepository) BranchTags(branchName string) []string {
+	return noop.repo.BranchTags(branchName)
+}
+
+// Files reads the files of the given revision, because reading doesn't change anything.
+func (noop *NoOpRepository) Files(revision string) ([]File, error) {
+	return noop.repo.Files(revision)
+}
+
+// ReachableTags lists the tags reachable from the given revision, because reading doesn't change anything.
+func (noop *NoOpRepository) ReachableTags(revision string) []string {
+	return noop.repo.ReachableTags(revision)
+}
+
+// Commits lists the commits between the given revisions, because reading doesn't change anything.
+func (noop *NoOpRepository) Commits(from, to string) ([]Commit, error) {
+	return noop.repo.Commits(from, to)
 }
 
 // IsSafe does nothing.
@@ -274,12 +738,67 @@ func (noop *NoOpRepository) CreateTag(tag string) error {
 	return nil
 }
 
+// ResolveRevision returns the commit hash of the given revision, because reading doesn't change anything.
+func (noop *NoOpRepository) ResolveRevision(revision string) (string, error) {
+	return noop.repo.ResolveRevision(revision)
+}
+
+// RemoteReferences lists the references of the remote, because reading doesn't change anything.
+func (noop *NoOpRepository) RemoteReferences(ctx context.Context) (map[string]string, error) {
+	return noop.repo.RemoteReferences(ctx)
+}
+
+// FetchTags does nothing.
+func (noop *NoOpRepository) FetchTags(ctx context.Context) error {
+	return nil
+}
+
+// CreateOrphanCommit does nothing.
+func (noop *NoOpRepository) CreateOrphanCommit(message string) (string, error) {
+	return "", nil
+}
+
+// CreateTagObject does nothing and returns the commit hash.
+func (noop *NoOpRepository) CreateTagObject(name, hash, message string) (string, error) {
+	return hash, nil
+}
+
+// SetReference does nothing.
+func (noop *NoOpRepository) SetReference(name, hash string) error {
+	return nil
+}
+
+// RemoveReference does nothing.
+func (noop *NoOpRepository) RemoveReference(name string) error {
+	return nil
+}
+
 // DeleteTag does nothing.
 func (noop *NoOpRepository) DeleteTag(tag string) error {
 	return nil
 }
 
+// CurrentBranch returns the name of the checked out branch, because reading doesn't change anything.
+func (noop *NoOpRepository) CurrentBranch() (string, error) {
+	return noop.repo.CurrentBranch()
+}
+
+// Branches lists the local and remote branches, because reading doesn't change anything.
+func (noop *NoOpRepository) Branches() ([]string, error) {
+	return noop.repo.Branches()
+}
+
+// RemoteURL returns the URL of the remote, because reading doesn't change anything.
+func (noop *NoOpRepository) RemoteURL() (string, error) {
+	return noop.repo.RemoteURL()
+}
+
+// Commit does nothing.
+func (noop *NoOpRepository) Commit(message string, files ...string) (string, error) {
+	return "", nil
+}
+
 // Push does nothing.
-func (noop *NoOpRepository) Push(ctx context.Context) error {
+func (noop *NoOpRepository) Push(ctx context.Context, refSpecs ...string) error {
 	return nil
 }
@@ -0,0 +1,381 @@
+package repository
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/exaring/release-cli/pkg/releasetest"
+	"github.com/stretchr/testify/assert"
+)
+
+// client is the interface of the backends, which is checked by the conformance tests.
+type client interface {
+	LatestCommitHash() string
+	Files(revision string) ([]File, error)
+	ExistsTag(version string) (bool, error)
+	Tags() []string
+	BranchTags(branchName string) []string
+	CurrentBranch() (string, error)
+	RemoteURL() (string, error)
+	ReachableTags(revision string) []string
+	Commits(from, to string) ([]Commit, error)
+	IsSafe(ctx context.Context) error
+	SetRemote(name string)
+	CreateTag(tag string) error
+	ResolveRevision(revision string) (string, error)
+	DeleteTag(tag string) error
+	Branches() ([]string, error)
+	RemoteReferences(ctx context.Context) (map[string]string, error)
+	FetchTags(ctx context.Context) error
+	CreateOrphanCommit(message string) (string, error)
+	CreateTagObject(name, hash, message string) (string, error)
+	SetReference(name, hash string) error
+	RemoveReference(name string) error
+	Commit(message string, files ...string) (string, error)
+	Push(ctx context.Context, refSpecs ...string) error
+	Path() string
+	GitDir() string
+}
+
+// backends opens the working tree with each backend.
+var backends = map[string]func(path string) (client, error){
+	GoGit: func(path string) (client, error) {
+		return New(path)
+	},
+	Command: func(path string) (client, error) {
+		return NewExec(path)
+	},
+}
+
+// fixture is a working tree with a bare origin. The master branch has the commits "Add a", which is tagged by the
+// lightweight tag v1.0.0, and "Add b", which is tagged by the annotated tag v1.1.0. The feature branch has the commit
+// "Add c" on top of "Add a", which is tagged by v2.0.0-RC1. Both branches are pushed and master is checked out.
+type fixture struct {
+	*releasetest.Repository
+	// commits maps the commit messages to the commit hashes.
+	commits map[string]string
+}
+
+func newFixture(t *testing.T) *fixture {
+	repo := releasetest.New(t,
+		releasetest.File("a.txt", "a\n"),
+		releasetest.Commit("Add a"),
+		releasetest.Tag("v1.0.0"),
+		releasetest.Executable("b.sh", "#!/bin/sh\n"),
+		releasetest.Commit("Add b"),
+		releasetest.AnnotatedTag("v1.1.0", "Release v1.1.0"),
+		releasetest.Checkout("v1.0.0"),
+		releasetest.NewBranch("feature"),
+		releasetest.File("c.txt", "c\n"),
+		releasetest.Commit("Add c"),
+		releasetest.Tag("v2.0.0-RC1"),
+		releasetest.Checkout("master"),
+		releasetest.Push(),
+	)
+	return &fixture{Repository: repo, commits: map[string]string{
+		"Add a": repo.Hash("v1.0.0"),
+		"Add b": repo.Hash("v1.1.0"),
+		"Add c": repo.Hash("feature"),
+	}}
+}
+
+// conform runs the test against a new fixture with each backend.
+func conform(t *testing.T, test func(t *testing.T, f *fixture, c client)) {
+	for name, open := range backends {
+		t.Run(name, func(t *testing.T) {
+			f := newFixture(t)
+			defer f.Remove()
+
+			c, err := open(f.Path)
+			assert.NoError(t, err)
+			test(t, f, c)
+		})
+	}
+}
+
+// refs returns the sorted tags in the notation of git show-ref.
+func (f *fixture) refs(tags ...string) []string {
+	var refs = make([]string, 0, len(tags))
+	for _, tag := range tags {
+		refs = append(refs, f.Git("rev-parse", "refs/tags/"+tag)+" refs/tags/"+tag)
+	}
+	sort.Strings(refs)
+	return refs
+}
+
+// sorted returns the sorted copy of the list.
+func sorted(list []string) []string {
+	list = append([]string{}, list...)
+	sort.Strings(list)
+	return list
+}
+
+func TestConformance_Tags(t *testing.T) {
+	conform(t, func(t *testing.T, f *fixture, c client) {
+		assert.Equal(t, f.refs("v1.0.0", "v1.1.0", "v2.0.0-RC1"), sorted(c.Tags()))
+		assert.Equal(t, f.refs("v1.0.0", "v1.1.0"), sorted(c.ReachableTags("HEAD")))
+		assert.Equal(t, f.refs("v1.0.0", "v2.0.0-RC1"), sorted(c.ReachableTags("feature")))
+		assert.Equal(t, f.refs("v1.0.0", "v1.1.0"), sorted(c.BranchTags("master")))
+		assert.Empty(t, c.BranchTags("unknown"))
+
+		exists, err := c.ExistsTag("refs/tags/v1.1.0")
+		assert.NoError(t, err)
+		assert.True(t, exists)
+		exists, err = c.ExistsTag("refs/tags/v1.2.0")
+		assert.NoError(t, err)
+		assert.False(t, exists)
+
+		assert.NoError(t, c.CreateTag("v1.2.0"))
+		assert.Equal(t, f.commits["Add b"], f.Git("rev-parse", "refs/tags/v1.2.0"))
+		assert.NoError(t, c.DeleteTag("v1.2.0"))
+		assert.Equal(t, f.refs("v1.0.0", "v1.1.0", "v2.0.0-RC1"), sorted(c.Tags()))
+	})
+}
+
+func TestConformance_Revisions(t *testing.T) {
+	conform(t, func(t *testing.T, f *fixture, c client) {
+		assert.Equal(t, f.commits["Add b"], c.LatestCommitHash())
+
+		for revision, commit := range map[string]string{
+			"HEAD":              "Add b",
+			"v1.0.0":            "Add a",
+			"v1.1.0":            "Add b",
+			"refs/tags/v1.1.0":  "Add b",
+			"feature":           "Add c",
+			f.commits["Add c"]:  "Add c",
+			"refs/heads/master": "Add b",
+		} {
+			hash, err := c.ResolveRevision(revision)
+			assert.NoError(t, err, revision)
+			assert.Equal(t, f.commits[commit], hash, revision)
+		}
+		_, err := c.ResolveRevision("v9.9.9")
+		assert.Error(t, err)
+
+		branch, err := c.CurrentBranch()
+		assert.NoError(t, err)
+		assert.Equal(t, "master", branch)
+		f.Git("checkout", "--quiet", "--detach")
+		_, err = c.CurrentBranch()
+		assert.EqualError(t, err, "the HEAD is detached")
+
+		f.Git("branch", "local")
+		branches, err := c.Branches()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"feature", "local", "master"}, branches)
+
+		url, err := c.RemoteURL()
+		assert.NoError(t, err)
+		assert.Equal(t, f.Origin, url)
+	})
+}
+
+func TestConformance_Commits(t *testing.T) {
+	conform(t, func(t *testing.T, f *fixture, c client) {
+		commits, err := c.Commits("v1.0.0", "HEAD")
+		assert.NoError(t, err)
+		if assert.Len(t, commits, 1) {
+			assert.Equal(t, f.commits["Add b"], commits[0].Hash)
+			assert.Equal(t, "Add b\n", commits[0].Message)
+			assert.Equal(t, releasetest.Name, commits[0].Author)
+			assert.True(t, releasetest.Epoch.Add(time.Minute).Equal(commits[0].When), commits[0].When)
+		}
+
+		commits, err = c.Commits("", "feature")
+		assert.NoError(t, err)
+		var messages []string
+		for _, commit := range commits {
+			messages = append(messages, commit.Message)
+		}
+		assert.Equal(t, []string{"Add c\n", "Add a\n"}, messages)
+
+		_, err = c.Commits("v9.9.9", "HEAD")
+		assert.Error(t, err)
+	})
+}
+
+func TestConformance_Files(t *testing.T) {
+	conform(t, func(t *testing.T, f *fixture, c client) {
+		files, err := c.Files("v1.1.0")
+		assert.NoError(t, err)
+		assert.Equal(t, []File{
+			{Path: "a.txt", Mode: 0644, Data: []byte("a\n")},
+			{Path: "b.sh", Mode: 0755, Data: []byte("#!/bin/sh\n")},
+		}, files)
+
+		assert.NoError(t, os.MkdirAll(filepath.Join(f.Path, "pkg"), 0755))
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(f.Path, "pkg", "d.txt"), []byte("d"), 0644))
+		assert.NoError(t, os.Remove(filepath.Join(f.Path, "a.txt")))
+		hash, err := c.Commit("Add d", "pkg/d.txt", "a.txt")
+		assert.NoError(t, err)
+		assert.Equal(t, hash, c.LatestCommitHash())
+		assert.Equal(t, "Add d", f.Git("log", "-1", "--format=%s"))
+		assert.Equal(t, "", f.Git("status", "--porcelain"))
+
+		files, err = c.Files("HEAD")
+		assert.NoError(t, err)
+		assert.Equal(t, []File{
+			{Path: "b.sh", Mode: 0755, Data: []byte("#!/bin/sh\n")},
+			{Path: "pkg/d.txt", Mode: 0644, Data: []byte("d")},
+		}, files)
+	})
+}
+
+func TestConformance_IsSafe(t *testing.T) {
+	tests := []struct {
+		name   string
+		steps  []releasetest.Step
+		remote string
+		safe   bool
+	}{
+		{name: "clean", safe: true},
+		{name: "ahead", steps: []releasetest.Step{releasetest.Commit("Unpushed change")}, safe: true},
+		{name: "modified", steps: []releasetest.Step{releasetest.File("a.txt", "changed")}},
+		{name: "untracked", steps: []releasetest.Step{releasetest.File("new.txt", "new")}},
+		{name: "staged", steps: []releasetest.Step{releasetest.File("a.txt", "changed"), releasetest.Stage("a.txt")}},
+		{name: "deleted", steps: []releasetest.Step{releasetest.Delete("a.txt")}},
+		{name: "behind", steps: []releasetest.Step{releasetest.Remote(releasetest.Commit("Concurrent change"))}},
+		{name: "diverged", steps: []releasetest.Step{
+			releasetest.Commit("Local change"),
+			releasetest.Remote(releasetest.Commit("Concurrent change")),
+		}},
+		{name: "behind without upstream", steps: []releasetest.Step{
+			func(r *releasetest.Repository) { r.Git("branch", "--unset-upstream") },
+			releasetest.Remote(releasetest.Commit("Concurrent change")),
+		}},
+		{name: "behind the release remote", steps: []releasetest.Step{
+			func(r *releasetest.Repository) { r.Git("remote", "add", "mirror", r.Origin) },
+			releasetest.Remote(releasetest.Commit("Concurrent change")),
+		}, remote: "mirror"},
+		{name: "new branch", steps: []releasetest.Step{
+			releasetest.NewBranch("hotfix"),
+			releasetest.Remote(releasetest.Commit("Concurrent change")),
+		}, safe: true},
+		{name: "without remote", steps: []releasetest.Step{func(r *releasetest.Repository) {
+			r.Git("remote", "remove", "origin")
+		}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conform(t, func(t *testing.T, f *fixture, c client) {
+				f.Apply(tt.steps...)
+				if tt.remote != "" {
+					c.SetRemote(tt.remote)
+				}
+				err := c.IsSafe(context.Background())
+				if tt.safe {
+					assert.NoError(t, err)
+				} else {
+					assert.Error(t, err)
+				}
+			})
+		})
+	}
+}
+
+func TestConformance_References(t *testing.T) {
+	conform(t, func(t *testing.T, f *fixture, c client) {
+		ctx := context.Background()
+
+		refs, err := c.RemoteReferences(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, f.commits["Add b"], refs["refs/heads/master"])
+		assert.Equal(t, f.commits["Add a"], refs["refs/tags/v1.0.0"])
+		assert.Equal(t, f.Git("rev-parse", "refs/tags/v1.1.0"), refs["refs/tags/v1.1.0"])
+
+		tag, err := c.CreateTagObject("v1.2.0", f.commits["Add b"], "Release v1.2.0\n\n- Add b\n\n")
+		assert.NoError(t, err)
+		assert.Equal(t, "tag", f.Git("cat-file", "-t", tag))
+		object := f.Git("cat-file", "tag", tag)
+		assert.Contains(t, object, "object "+f.commits["Add b"]+"\ntype commit\ntag v1.2.0\n")
+		assert.True(t, strings.HasSuffix(object, "\n\nRelease v1.2.0\n\n- Add b"), object)
+		_, err = c.ResolveRevision("refs/tags/v1.2.0")
+		assert.Error(t, err, "the tag object isn't referenced yet")
+
+		assert.NoError(t, c.SetReference("refs/tags/v1.2.0", tag))
+		hash, err := c.ResolveRevision("v1.2.0")
+		assert.NoError(t, err)
+		assert.Equal(t, f.commits["Add b"], hash)
+		assert.NoError(t, c.Push(ctx, "refs/tags/v1.2.0:refs/tags/v1.2.0"))
+		refs, err = c.RemoteReferences(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, tag, refs["refs/tags/v1.2.0"])
+
+		assert.NoError(t, c.RemoveReference("refs/tags/v1.2.0"))
+		_, err = c.ResolveRevision("v1.2.0")
+		assert.Error(t, err)
+		assert.NoError(t, c.FetchTags(ctx))
+		hash, err = c.ResolveRevision("v1.2.0")
+		assert.NoError(t, err)
+		assert.Equal(t, f.commits["Add b"], hash)
+
+		orphan, err := c.CreateOrphanCommit("release lock")
+		assert.NoError(t, err)
+		assert.Equal(t, "", f.Git("log", "-1", "--format=%P", orphan))
+		assert.Equal(t, "", f.Git("ls-tree", orphan))
+		assert.NoError(t, c.SetReference("refs/release-lock/current", orphan))
+		assert.NoError(t, c.Push(ctx, "refs/release-lock/current:refs/release-lock/current"))
+		refs, err = c.RemoteReferences(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, orphan, refs["refs/release-lock/current"])
+	})
+}
+
+func TestConformance_AtomicPush(t *testing.T) {
+	conform(t, func(t *testing.T, f *fixture, c client) {
+		f.Apply(releasetest.Remote(releasetest.Commit("Concurrent change")))
+
+		// the tag would be accepted, but the branch isn't a fast-forward. The exec backend pushes atomically, go-git
+		// refuses the whole push, because it checks the fast-forward before sending the references.
+		assert.NoError(t, c.SetReference("refs/tags/v1.2.0", f.commits["Add b"]))
+		assert.Error(t, c.Push(context.Background(), "refs/tags/v1.2.0:refs/tags/v1.2.0",
+			"refs/heads/master:refs/heads/master"))
+
+		refs, err := c.RemoteReferences(context.Background())
+		assert.NoError(t, err)
+		assert.NotContains(t, refs, "refs/tags/v1.2.0")
+	})
+}
+
+func TestConformance_Paths(t *testing.T) {
+	conform(t, func(t *testing.T, f *fixture, c client) {
+		local, err := filepath.EvalSymlinks(f.Path)
+		assert.NoError(t, err)
+		path, err := filepath.EvalSymlinks(c.Path())
+		assert.NoError(t, err)
+		assert.Equal(t, local, path)
+		gitDir, err := filepath.EvalSymlinks(c.GitDir())
+		assert.NoError(t, err)
+		assert.Equal(t, filepath.Join(local, ".git"), gitDir)
+	})
+}
+
+func TestConformance_NoOp(t *testing.T) {
+	conform(t, func(t *testing.T, f *fixture, c client) {
+		ctx := context.Background()
+		noop := NewNoOp(c)
+
+		assert.Equal(t, f.commits["Add b"], noop.LatestCommitHash())
+		assert.Equal(t, sorted(c.Tags()), sorted(noop.Tags()))
+		branch, err := noop.CurrentBranch()
+		assert.NoError(t, err)
+		assert.Equal(t, "master", branch)
+		refs, err := noop.RemoteReferences(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, f.commits["Add b"], refs["refs/heads/master"])
+
+		assert.NoError(t, noop.SetReference("refs/tags/v1.2.0", f.commits["Add b"]))
+		assert.NoError(t, noop.Push(ctx, "refs/tags/v1.2.0:refs/tags/v1.2.0"))
+		_, err = c.ResolveRevision("refs/tags/v1.2.0")
+		assert.Error(t, err)
+		refs, err = c.RemoteReferences(ctx)
+		assert.NoError(t, err)
+		assert.NotContains(t, refs, "refs/tags/v1.2.0")
+	})
+}
@@ -0,0 +1,103 @@
+// Package restapi sends JSON requests to the REST APIs of forges and issue trackers.
+package restapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// ErrNotFound is returned if the requested resource doesn't exist.
+var ErrNotFound = errors.New("not found")
+
+// Client sends JSON requests to an API.
+type Client struct {
+	// BaseURL is the URL of the API, which relative paths are resolved against.
+	BaseURL string
+	// Auth sets the authentication header of a request.
+	Auth func(r *http.Request)
+	// HTTP sends the requests. It defaults to http.DefaultClient.
+	HTTP *http.Client
+}
+
+// Upload is a request body, which isn't encoded as JSON.
+type Upload struct {
+	Body        io.Reader
+	ContentType string
+}
+
+// Do sends the request with the JSON body and decodes the JSON response into out. Absolute URLs aren't resolved
+// against the base URL. It returns ErrNotFound for 404 responses.
+func (c *Client) Do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	var contentType string
+	switch b := body.(type) {
+	case nil:
+	case Upload:
+		reader, contentType = b.Body, b.ContentType
+	default:
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader, contentType = bytes.NewReader(data), "application/json"
+	}
+
+	target := path
+	if !strings.HasPrefix(path, "http://") && !strings.HasPrefix(path, "https://") {
+		target = strings.TrimSuffix(c.BaseURL, "/") + path
+	}
+	req, err := http.NewRequest(method, target, reader)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "application/json")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.Auth != nil {
+		c.Auth(req)
+	}
+
+	client := c.HTTP
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if resp.StatusCode >= 300 {
+		message, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%v %v: %v %v", method, path, resp.Status, strings.TrimSpace(string(message)))
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("%v %v: invalid response: %w", method, path, err)
+	}
+	return nil
+}
+
+// TokenAuth returns the authentication with the token in the given header, e.g. Authorization: token <token>. Requests
+// aren't authenticated without a token.
+func TokenAuth(header, prefix, token string) func(r *http.Request) {
+	return func(r *http.Request) {
+		if token != "" {
+			r.Header.Set(header, prefix+token)
+		}
+	}
+}
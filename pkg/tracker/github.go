@@ -0,0 +1,61 @@
+package tracker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"github.com/exaring/release-cli/pkg/restapi"
+)
+
+// issueNumber matches issue references like #123 and Fixes #123.
+var issueNumber = regexp.MustCompile(`(?:^|[^\w/])` + closingKeywords + `#(\d+)\b`)
+
+// gitHub updates the issues with the REST API of GitHub.
+type gitHub struct {
+	restapi.Client
+	repository string
+}
+
+func newGitHub(opts Options) *gitHub {
+	if opts.BaseURL == "" {
+		opts.BaseURL = "https://api.github.com"
+	}
+	return &gitHub{
+		Client: restapi.Client{
+			BaseURL: opts.BaseURL,
+			Auth:    restapi.TokenAuth("Authorization", "token ", opts.Token),
+			HTTP:    opts.Client,
+		},
+		repository: opts.Repository,
+	}
+}
+
+// References returns the issue numbers of references like #123.
+func (g *gitHub) References(messages []string) []Reference {
+	return references(messages, issueNumber)
+}
+
+// Update comments on, labels and closes the issue.
+func (g *gitHub) Update(ctx context.Context, id string, u Update) error {
+	issue := "/repos/" + g.repository + "/issues/" + id
+
+	if u.Comment != "" {
+		if err := g.Do(ctx, http.MethodPost, issue+"/comments", map[string]string{"body": u.Comment}, nil); err != nil {
+			return fmt.Errorf("failed to comment on the issue #%v: %w", id, err)
+		}
+	}
+	if u.Label != "" {
+		labels := map[string][]string{"labels": {u.Label}}
+		if err := g.Do(ctx, http.MethodPost, issue+"/labels", labels, nil); err != nil {
+			return fmt.Errorf("failed to label the issue #%v: %w", id, err)
+		}
+	}
+	if u.Close {
+		if err := g.Do(ctx, http.MethodPatch, issue, map[string]string{"state": "closed"}, nil); err != nil {
+			return fmt.Errorf("failed to close the issue #%v: %w", id, err)
+		}
+	}
+	return nil
+}
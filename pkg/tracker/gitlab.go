@@ -0,0 +1,60 @@
+package tracker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/exaring/release-cli/pkg/restapi"
+)
+
+// gitLab updates the issues with the REST API of GitLab.
+type gitLab struct {
+	restapi.Client
+	project string
+}
+
+func newGitLab(opts Options) *gitLab {
+	if opts.BaseURL == "" {
+		opts.BaseURL = "https://gitlab.com/api/v4"
+	}
+	return &gitLab{
+		Client: restapi.Client{
+			BaseURL: opts.BaseURL,
+			Auth:    restapi.TokenAuth("PRIVATE-TOKEN", "", opts.Token),
+			HTTP:    opts.Client,
+		},
+		project: url.PathEscape(opts.Repository),
+	}
+}
+
+// References returns the issue numbers of references like #123.
+func (g *gitLab) References(messages []string) []Reference {
+	return references(messages, issueNumber)
+}
+
+// Update comments on, labels and closes the issue.
+func (g *gitLab) Update(ctx context.Context, id string, u Update) error {
+	issue := "/projects/" + g.project + "/issues/" + id
+
+	if u.Comment != "" {
+		if err := g.Do(ctx, http.MethodPost, issue+"/notes", map[string]string{"body": u.Comment}, nil); err != nil {
+			return fmt.Errorf("failed to comment on the issue #%v: %w", id, err)
+		}
+	}
+
+	var edit = make(map[string]string)
+	if u.Label != "" {
+		edit["add_labels"] = u.Label
+	}
+	if u.Close {
+		edit["state_event"] = "close"
+	}
+	if len(edit) > 0 {
+		if err := g.Do(ctx, http.MethodPut, issue, edit, nil); err != nil {
+			return fmt.Errorf("failed to edit the issue #%v: %w", id, err)
+		}
+	}
+	return nil
+}
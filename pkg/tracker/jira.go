@@ -0,0 +1,93 @@
+package tracker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/exaring/release-cli/pkg/restapi"
+)
+
+// jira updates the issues with the REST API v2 of Jira.
+type jira struct {
+	restapi.Client
+	pattern    *regexp.Regexp
+	transition string
+}
+
+func newJira(opts Options) *jira {
+	project := `[A-Z][A-Z0-9_]*`
+	if opts.Project != "" {
+		project = regexp.QuoteMeta(opts.Project)
+	}
+
+	auth := restapi.TokenAuth("Authorization", "Bearer ", opts.Token)
+	if opts.User != "" {
+		auth = func(r *http.Request) {
+			r.SetBasicAuth(opts.User, opts.Token)
+		}
+	}
+
+	return &jira{
+		Client:     restapi.Client{BaseURL: opts.BaseURL, Auth: auth, HTTP: opts.Client},
+		pattern:    regexp.MustCompile(closingKeywords + `\b(` + project + `-\d+)\b`),
+		transition: opts.Transition,
+	}
+}
+
+// References returns the issue keys of references like PROJ-45.
+func (j *jira) References(messages []string) []Reference {
+	return references(messages, j.pattern)
+}
+
+// Update comments on the issue, adds the fix version and transitions the issue.
+func (j *jira) Update(ctx context.Context, id string, u Update) error {
+	issue := "/rest/api/2/issue/" + id
+
+	if u.Comment != "" {
+		if err := j.Do(ctx, http.MethodPost, issue+"/comment", map[string]string{"body": u.Comment}, nil); err != nil {
+			return fmt.Errorf("failed to comment on the issue %v: %w", id, err)
+		}
+	}
+	if u.FixVersion {
+		body := map[string]interface{}{"update": map[string]interface{}{
+			"fixVersions": []interface{}{map[string]interface{}{"add": map[string]string{"name": u.Version}}},
+		}}
+		if err := j.Do(ctx, http.MethodPut, issue, body, nil); err != nil {
+			return fmt.Errorf("failed to add the fix version to the issue %v: %w", id, err)
+		}
+	}
+	if u.Close {
+		if err := j.close(ctx, issue); err != nil {
+			return fmt.Errorf("failed to transition the issue %v: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// close executes the configured transition of the issue. The transition is looked up by name, because its ID depends
+// on the workflow of the project.
+func (j *jira) close(ctx context.Context, issue string) error {
+	if j.transition == "" {
+		return fmt.Errorf("no transition is configured")
+	}
+
+	var available struct {
+		Transitions []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"transitions"`
+	}
+	if err := j.Do(ctx, http.MethodGet, issue+"/transitions", nil, &available); err != nil {
+		return err
+	}
+	for _, t := range available.Transitions {
+		if strings.EqualFold(t.Name, j.transition) {
+			body := map[string]interface{}{"transition": map[string]string{"id": t.ID}}
+			return j.Do(ctx, http.MethodPost, issue+"/transitions", body, nil)
+		}
+	}
+	return fmt.Errorf("the transition %q isn't available", j.transition)
+}
@@ -0,0 +1,114 @@
+// Package tracker extracts the issue references of released commits and comments on, labels and closes the issues in
+// issue trackers like GitHub issues, GitLab issues and Jira.
+package tracker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+)
+
+const (
+	// GitHub is the provider of GitHub issues.
+	GitHub = "github"
+	// GitLab is the provider of GitLab issues.
+	GitLab = "gitlab"
+	// Jira is the provider of the Jira REST API.
+	Jira = "jira"
+)
+
+// Providers lists all supported providers.
+var Providers = []string{GitHub, GitLab, Jira}
+
+// closingKeywords precede the references of issues which are closed by a commit, e.g. Fixes #123.
+const closingKeywords = `(?i:\b(close[sd]?|fix(?:e[sd])?|resolve[sd]?)\s*:?\s+)?`
+
+// Reference is an issue which is referenced by a released commit.
+type Reference struct {
+	// ID is the issue number or key, e.g. 123 or PROJ-45.
+	ID string
+	// Close reports whether a commit references the issue with a closing keyword like Fixes.
+	Close bool
+}
+
+// Update describes the changes of a released issue.
+type Update struct {
+	// Version is the released tag, e.g. v1.2.3.
+	Version string
+	// Comment is the comment of the issue.
+	Comment string
+	// Label is added to GitHub and GitLab issues.
+	Label string
+	// FixVersion adds the version to the fix versions of Jira issues.
+	FixVersion bool
+	// Close closes the issue or transitions Jira issues.
+	Close bool
+}
+
+// Tracker updates the issues of a release.
+type Tracker interface {
+	// References returns the referenced issues of the commit messages in the order of their first reference.
+	References(messages []string) []Reference
+	// Update comments on, labels and closes the issue.
+	Update(ctx context.Context, id string, u Update) error
+}
+
+// Options configure a tracker.
+type Options struct {
+	// BaseURL is the URL of the API, e.g. https://api.github.com or https://jira.example.com.
+	BaseURL string
+	// Token authenticates the requests.
+	Token string
+	// User is the user of Jira tokens, which are sent with basic authentication. Jira tokens without user are sent as
+	// bearer token.
+	User string
+	// Repository is the path of the repository of GitHub and GitLab issues, e.g. exaring/release-cli.
+	Repository string
+	// Project is the key of the Jira project, whose issues are referenced. Any project matches if it's empty.
+	Project string
+	// Transition is the name of the Jira transition, which closes an issue, e.g. Done.
+	Transition string
+	// Client sends the requests. It defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+// New returns the tracker of the given kind.
+func New(kind string, opts Options) (Tracker, error) {
+	switch kind {
+	case GitHub, GitLab:
+		if opts.Repository == "" {
+			return nil, fmt.Errorf("the repository of the %v issues is unknown", kind)
+		}
+		if kind == GitHub {
+			return newGitHub(opts), nil
+		}
+		return newGitLab(opts), nil
+	case Jira:
+		if opts.BaseURL == "" {
+			return nil, fmt.Errorf("the URL of Jira is unknown")
+		}
+		return newJira(opts), nil
+	default:
+		return nil, fmt.Errorf("unknown tracker %q, expected one of %v", kind, Providers)
+	}
+}
+
+// references returns the issues of the pattern, whose last group is the issue ID, in the order of their first
+// reference. An issue is closed if any reference has a closing keyword.
+func references(messages []string, pattern *regexp.Regexp) []Reference {
+	var refs []Reference
+	var index = make(map[string]int)
+	for _, message := range messages {
+		for _, m := range pattern.FindAllStringSubmatch(message, -1) {
+			id, close := m[len(m)-1], m[1] != ""
+			if i, ok := index[id]; ok {
+				refs[i].Close = refs[i].Close || close
+				continue
+			}
+			index[id] = len(refs)
+			refs = append(refs, Reference{ID: id, Close: close})
+		}
+	}
+	return refs
+}
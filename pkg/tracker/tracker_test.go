@@ -0,0 +1,174 @@
+package tracker
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReferences(t *testing.T) {
+	tt := []struct {
+		name     string
+		kind     string
+		project  string
+		messages []string
+		refs     []Reference
+	}{
+		{
+			name:     "github",
+			kind:     GitHub,
+			messages: []string{"Add retractions (#12)", "Fixes #7: empty tags\n\nSee #12 and acme/other#3."},
+			refs:     []Reference{{ID: "12"}, {ID: "7", Close: true}},
+		},
+		{
+			name:     "github closing keywords",
+			kind:     GitHub,
+			messages: []string{"closes #1", "Resolved: #2", "fix #3", "prefix#4", "Refs #1"},
+			refs:     []Reference{{ID: "1", Close: true}, {ID: "2", Close: true}, {ID: "3", Close: true}},
+		},
+		{
+			name:     "jira",
+			kind:     Jira,
+			messages: []string{"PROJ-4 Add retractions", "Fixes OPS-12 and PROJ-4", "lowercase proj-5"},
+			refs:     []Reference{{ID: "PROJ-4"}, {ID: "OPS-12", Close: true}},
+		},
+		{
+			name:     "jira project",
+			kind:     Jira,
+			project:  "PROJ",
+			messages: []string{"PROJ-4 Add retractions", "Fixes OPS-12 and PROJ-4", "Resolves PROJ-4"},
+			refs:     []Reference{{ID: "PROJ-4", Close: true}},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tracker, err := New(tc.kind, Options{BaseURL: "https://example.com", Repository: "acme/app",
+				Project: tc.project})
+			assert.NoError(t, err)
+			assert.Equal(t, tc.refs, tracker.References(tc.messages))
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	tt := []struct {
+		name      string
+		kind      string
+		opts      Options
+		update    Update
+		responses map[string]string
+		requests  []string
+		err       string
+	}{
+		{
+			name:   "github",
+			kind:   GitHub,
+			update: Update{Version: "v1.3.0", Comment: "Released in v1.3.0", Label: "released", Close: true},
+			requests: []string{
+				`POST /repos/acme/app/issues/12/comments {"body":"Released in v1.3.0"}`,
+				`POST /repos/acme/app/issues/12/labels {"labels":["released"]}`,
+				`PATCH /repos/acme/app/issues/12 {"state":"closed"}`,
+			},
+		},
+		{
+			name:   "gitlab",
+			kind:   GitLab,
+			update: Update{Version: "v1.3.0", Comment: "Released in v1.3.0", Label: "released", Close: true},
+			requests: []string{
+				`POST /projects/acme%2Fapp/issues/12/notes {"body":"Released in v1.3.0"}`,
+				`PUT /projects/acme%2Fapp/issues/12 {"add_labels":"released","state_event":"close"}`,
+			},
+		},
+		{
+			name:   "gitlab comment only",
+			kind:   GitLab,
+			update: Update{Version: "v1.3.0", Comment: "Released in v1.3.0"},
+			requests: []string{
+				`POST /projects/acme%2Fapp/issues/12/notes {"body":"Released in v1.3.0"}`,
+			},
+		},
+		{
+			name:   "jira",
+			kind:   Jira,
+			opts:   Options{Transition: "done"},
+			update: Update{Version: "v1.3.0", Comment: "Released in v1.3.0", FixVersion: true, Close: true},
+			responses: map[string]string{
+				"GET /rest/api/2/issue/12/transitions": `{"transitions": [{"id": "11", "name": "In Progress"}, ` +
+					`{"id": "31", "name": "Done"}]}`,
+			},
+			requests: []string{
+				`POST /rest/api/2/issue/12/comment {"body":"Released in v1.3.0"}`,
+				`PUT /rest/api/2/issue/12 {"update":{"fixVersions":[{"add":{"name":"v1.3.0"}}]}}`,
+				`GET /rest/api/2/issue/12/transitions `,
+				`POST /rest/api/2/issue/12/transitions {"transition":{"id":"31"}}`,
+			},
+		},
+		{
+			name:   "jira unknown transition",
+			kind:   Jira,
+			opts:   Options{Transition: "Shipped"},
+			update: Update{Version: "v1.3.0", Close: true},
+			responses: map[string]string{
+				"GET /rest/api/2/issue/12/transitions": `{"transitions": [{"id": "31", "name": "Done"}]}`,
+			},
+			requests: []string{`GET /rest/api/2/issue/12/transitions `},
+			err:      `failed to transition the issue 12: the transition "Shipped" isn't available`,
+		},
+		{
+			name:      "error",
+			kind:      GitHub,
+			update:    Update{Version: "v1.3.0", Comment: "Released in v1.3.0"},
+			responses: map[string]string{"POST /repos/acme/app/issues/12/comments": "404"},
+			requests:  []string{`POST /repos/acme/app/issues/12/comments {"body":"Released in v1.3.0"}`},
+			err:       "failed to comment on the issue #12: not found",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var requests []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := ioutil.ReadAll(r.Body)
+				key := r.Method + " " + r.URL.EscapedPath()
+				requests = append(requests, key+" "+string(body))
+
+				switch response := tc.responses[key]; response {
+				case "404":
+					http.NotFound(w, r)
+				case "":
+					_, _ = w.Write([]byte(`{}`))
+				default:
+					_, _ = w.Write([]byte(response))
+				}
+			}))
+			defer server.Close()
+
+			opts := tc.opts
+			opts.BaseURL, opts.Repository, opts.Token = server.URL, "acme/app", "secret"
+			tracker, err := New(tc.kind, opts)
+			assert.NoError(t, err)
+
+			err = tracker.Update(context.Background(), "12", tc.update)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.requests, requests)
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(GitHub, Options{})
+	assert.EqualError(t, err, "the repository of the github issues is unknown")
+	_, err = New(Jira, Options{})
+	assert.EqualError(t, err, "the URL of Jira is unknown")
+	_, err = New("bugzilla", Options{})
+	assert.EqualError(t, err, `unknown tracker "bugzilla", expected one of [github gitlab jira]`)
+}
@@ -0,0 +1,387 @@
+// Package transaction changes local and remote git references of a release as one unit. Every completed step is
+// recorded in a journal file and undone in reverse order if a later step fails, so that an interrupted release can be
+// finished or rolled back afterwards.
+package transaction
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// Local changes the local references.
+	Local = "local"
+	// Remote pushes the local references to the remote.
+	Remote = "remote"
+	// Hook runs a hook of the caller, which can't be undone.
+	Hook = "hook"
+)
+
+// ErrUnfinished is returned if the journal of an unfinished transaction exists.
+var ErrUnfinished = errors.New("an unfinished release exists, finish or roll it back with \"release recover\"")
+
+// Ref is the change of a reference.
+type Ref struct {
+	// Name is the full reference name, e.g. refs/tags/v1.2.3.
+	Name string `json:"name"`
+	// Hash is the commit hash the reference points to after the change.
+	Hash string `json:"hash"`
+	// Previous is the commit hash the reference pointed to before the change. It's empty for new references.
+	Previous string `json:"previous,omitempty"`
+}
+
+// Step changes the references locally or on the remote or runs a hook.
+type Step struct {
+	Kind string `json:"kind"`
+	Refs []Ref  `json:"refs,omitempty"`
+	// Hook is the name of the hook of hook steps.
+	Hook string `json:"hook,omitempty"`
+	// Running reports whether the step was started, but neither completed nor failed, e.g. the process was killed.
+	Running bool `json:"running,omitempty"`
+	Done    bool `json:"done"`
+}
+
+// Journal is the recorded state of a transaction.
+type Journal struct {
+	// Name describes the transaction, e.g. the tag of the release.
+	Name    string    `json:"name"`
+	Started time.Time `json:"started"`
+	Steps   []Step    `json:"steps"`
+}
+
+// Fields are the details of a log entry.
+type Fields map[string]interface{}
+
+// Logger logs the progress of the steps. Applications adapt their logging library to it, e.g. logrus.
+type Logger interface {
+	// WithFields returns the logger, which adds the fields to each entry.
+	WithFields(fields Fields) Logger
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+// Repository changes the references of a repository.
+type Repository interface {
+	// SetReference creates or moves the reference to the commit hash.
+	SetReference(name, hash string) error
+	// RemoveReference deletes the reference.
+	RemoveReference(name string) error
+	// Push pushes the given ref specs of the local repo to the remote.
+	Push(ctx context.Context, refSpecs ...string) error
+	// RemoteReferences lists the references of the remote with their hashes.
+	RemoteReferences(ctx context.Context) (map[string]string, error)
+}
+
+// Transaction is a sequence of steps, which are undone in reverse order if a step fails.
+type Transaction struct {
+	Journal
+
+	repo   Repository
+	logger Logger
+	file   string
+	hook   func(ctx context.Context, name string) error
+}
+
+// Begin starts a new transaction, which is recorded in the journal file. The transaction isn't recorded if the file is
+// empty. It returns ErrUnfinished if the journal file already exists.
+func Begin(repo Repository, logger Logger, file, name string) (*Transaction, error) {
+	if file != "" {
+		if _, err := os.Stat(file); err == nil {
+			return nil, ErrUnfinished
+		}
+	}
+
+	return &Transaction{
+		Journal: Journal{Name: name, Started: time.Now()},
+		repo:    repo,
+		logger:  logger,
+		file:    file,
+	}, nil
+}
+
+// Open opens the unfinished transaction of the journal file.
+func Open(repo Repository, logger Logger, file string) (*Transaction, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Transaction{repo: repo, logger: logger, file: file}
+	if err := json.Unmarshal(data, &t.Journal); err != nil {
+		return nil, fmt.Errorf("invalid journal %v: %w", file, err)
+	}
+	return t, nil
+}
+
+// Add appends a pending step, which changes the references locally or on the remote.
+func (t *Transaction) Add(kind string, refs ...Ref) {
+	if len(refs) == 0 {
+		return
+	}
+	t.Steps = append(t.Steps, Step{Kind: kind, Refs: refs})
+}
+
+// AddHook appends a pending step, which runs the hook with the given name. A failing hook aborts the transaction like
+// any other step.
+func (t *Transaction) AddHook(name string) {
+	t.Steps = append(t.Steps, Step{Kind: Hook, Hook: name})
+}
+
+// SetHook sets the function which runs the hook steps. Hook steps are skipped without it, e.g. when an interrupted
+// transaction is recovered.
+func (t *Transaction) SetHook(hook func(ctx context.Context, name string) error) {
+	t.hook = hook
+}
+
+// Run executes all pending steps and removes the journal afterwards. If a step fails, all completed steps are undone
+// in reverse order. The journal is kept if the rollback fails as well. The rollback isn't canceled by the context, so
+// that an interrupted release is still undone.
+func (t *Transaction) Run(ctx context.Context) error {
+	for i := range t.Steps {
+		step := &t.Steps[i]
+		if step.Done {
+			continue
+		}
+
+		step.Running = true
+		if err := t.save(); err != nil {
+			return err
+		}
+
+		if err := t.do(ctx, *step); err != nil {
+			if rollbackErr := t.Rollback(context.Background()); rollbackErr != nil {
+				return fmt.Errorf("%w, the rollback failed as well: %v", err, rollbackErr)
+			}
+			return err
+		}
+
+		step.Running, step.Done = false, true
+		if err := t.save(); err != nil {
+			return err
+		}
+	}
+
+	return t.remove()
+}
+
+// Rollback undoes all completed steps in reverse order and removes the journal afterwards. A running step, which
+// failed or was interrupted, may have changed a part of its references. Its references are undone on their own as far
+// as possible, but remote references are only undone if they point to the hash of the step, because they may belong to
+// somebody else otherwise, e.g. the tag of a concurrent release, which rejected the push.
+func (t *Transaction) Rollback(ctx context.Context) error {
+	for i := len(t.Steps) - 1; i >= 0; i-- {
+		step := &t.Steps[i]
+		if step.Running {
+			t.undoPartial(ctx, *step)
+			step.Running = false
+		}
+		if !step.Done || step.Kind == Hook {
+			step.Done = false
+			continue
+		}
+
+		if err := t.undo(ctx, *step); err != nil {
+			return err
+		}
+		t.logger.WithFields(Fields{
+			"Kind": step.Kind,
+			"Refs": names(step.Refs),
+		}).Warnf("Undo the release step")
+
+		step.Done = false
+		if err := t.save(); err != nil {
+			return err
+		}
+	}
+
+	return t.remove()
+}
+
+// do executes the step.
+func (t *Transaction) do(ctx context.Context, step Step) error {
+	switch step.Kind {
+	case Local:
+		for _, ref := range step.Refs {
+			if err := t.repo.SetReference(ref.Name, ref.Hash); err != nil {
+				return fmt.Errorf("failed to set %v: %w", ref.Name, err)
+			}
+		}
+		t.logger.WithFields(Fields{
+			"Refs": names(step.Refs),
+		}).Debugf("Set the local references")
+	case Remote:
+		var refSpecs = make([]string, 0, len(step.Refs))
+		for _, ref := range step.Refs {
+			spec := fmt.Sprintf("%[1]v:%[1]v", ref.Name)
+			if ref.Previous != "" && strings.HasPrefix(ref.Name, "refs/tags/") {
+				// moved tags are only accepted by force
+				spec = "+" + spec
+			}
+			refSpecs = append(refSpecs, spec)
+		}
+		if err := t.repo.Push(ctx, refSpecs...); err != nil {
+			return fmt.Errorf("failed to push %v: %w", strings.Join(names(step.Refs), ", "), err)
+		}
+		t.logger.WithFields(Fields{
+			"Refs": names(step.Refs),
+		}).Debugf("Push the references to the remote")
+	case Hook:
+		if t.hook == nil {
+			t.logger.WithFields(Fields{
+				"Hook": step.Hook,
+			}).Warnf("Skip the hook, because it isn't available")
+			return nil
+		}
+		if err := t.hook(ctx, step.Hook); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown step kind %q", step.Kind)
+	}
+
+	return nil
+}
+
+// undo reverts the step. New references are removed and moved references are reset to their previous commit. Remote
+// references are only reverted if they still point to the hash of the step, so changes of others are never
+// overwritten, and remote branches are never reset, because it requires a force push.
+func (t *Transaction) undo(ctx context.Context, step Step) error {
+	switch step.Kind {
+	case Local:
+		for _, ref := range step.Refs {
+			if err := t.reset(ref); err != nil {
+				return fmt.Errorf("failed to reset %v: %w", ref.Name, err)
+			}
+		}
+	case Remote:
+		return t.undoRemote(ctx, step.Refs, false)
+	case Hook:
+		// the effects of hooks are unknown and can't be undone
+	default:
+		return fmt.Errorf("unknown step kind %q", step.Kind)
+	}
+
+	return nil
+}
+
+// undoRemote reverts the remote references, which still point to the hash of the step. The references of a partial
+// push, which failed or was interrupted, are skipped silently if they don't point to the hash, because they weren't
+// pushed or belong to somebody else.
+func (t *Transaction) undoRemote(ctx context.Context, refs []Ref, partial bool) error {
+	remote, err := t.repo.RemoteReferences(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list the remote references: %w", err)
+	}
+
+	var refSpecs = make([]string, 0, len(refs))
+	for _, ref := range refs {
+		switch {
+		case remote[ref.Name] != ref.Hash && partial:
+			t.logger.WithFields(Fields{
+				"Ref":    ref.Name,
+				"Remote": remote[ref.Name],
+			}).Debugf("Keep the remote reference, because the failed push didn't change it")
+		case remote[ref.Name] != ref.Hash:
+			t.logger.WithFields(Fields{
+				"Ref":    ref.Name,
+				"Remote": remote[ref.Name],
+			}).Warnf("Keep the remote reference, because it was changed meanwhile")
+		case ref.Previous != "" && strings.HasPrefix(ref.Name, "refs/heads/"):
+			t.logger.WithFields(Fields{
+				"Ref":  ref.Name,
+				"Hash": ref.Hash,
+			}).Warnf("Keep the remote branch, because it's never reset by force")
+		case ref.Previous == "":
+			refSpecs = append(refSpecs, ":"+ref.Name)
+		default:
+			// moved tags are reset by force pushing the local reference at the previous commit
+			if err := t.repo.SetReference(ref.Name, ref.Previous); err != nil {
+				return fmt.Errorf("failed to reset %v: %w", ref.Name, err)
+			}
+			refSpecs = append(refSpecs, fmt.Sprintf("+%[1]v:%[1]v", ref.Name))
+		}
+	}
+	if len(refSpecs) == 0 {
+		return nil
+	}
+	if err := t.repo.Push(ctx, refSpecs...); err != nil {
+		return fmt.Errorf("failed to reset the remote %v: %w", strings.Join(names(refs), ", "), err)
+	}
+	return nil
+}
+
+// undoPartial reverts every reference of the interrupted or failed step on its own, because it's unknown which
+// references were changed. A push, which isn't atomic, may have pushed a part of the references, which are only
+// reverted if they point to the hash of the step like in undo. Errors are only logged.
+func (t *Transaction) undoPartial(ctx context.Context, step Step) {
+	switch step.Kind {
+	case Remote:
+		if err := t.undoRemote(ctx, step.Refs, true); err != nil {
+			t.logger.WithFields(Fields{
+				"Refs":  names(step.Refs),
+				"error": err,
+			}).Warnf("Couldn't undo the remote references of the failed push, check whether they were pushed")
+		}
+	case Local:
+		for _, ref := range step.Refs {
+			if err := t.reset(ref); err != nil {
+				t.logger.WithFields(Fields{
+					"Ref":   ref.Name,
+					"error": err,
+				}).Debugf("Couldn't reset the local reference of the failed step")
+			}
+		}
+	}
+}
+
+// reset removes a new reference or moves it back to the previous commit.
+func (t *Transaction) reset(ref Ref) error {
+	if ref.Previous == "" {
+		return t.repo.RemoveReference(ref.Name)
+	}
+	return t.repo.SetReference(ref.Name, ref.Previous)
+}
+
+// save writes the journal file atomically.
+func (t *Transaction) save() error {
+	if t.file == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(t.Journal, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(t.file+".tmp", data, 0644); err != nil {
+		return fmt.Errorf("failed to write the journal: %w", err)
+	}
+	return os.Rename(t.file+".tmp", t.file)
+}
+
+// remove deletes the journal file.
+func (t *Transaction) remove() error {
+	if t.file == "" {
+		return nil
+	}
+	if err := os.Remove(t.file); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove the journal: %w", err)
+	}
+	return nil
+}
+
+// names returns the names of the references.
+func names(refs []Ref) []string {
+	var n = make([]string, len(refs))
+	for i, ref := range refs {
+		n[i] = ref.Name
+	}
+	return n
+}
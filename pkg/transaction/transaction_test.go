@@ -0,0 +1,305 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeRepository keeps the local and remote references in maps. Pushes of the refs in fail are rejected, but their
+// deletion is accepted like the deletion of a tag, which somebody else pushed. A partial push accepts the other refs
+// of a rejected push like a push, which isn't atomic.
+type fakeRepository struct {
+	local, remote map[string]string
+	fail          map[string]bool
+	partial       bool
+}
+
+func newFakeRepository() *fakeRepository {
+	return &fakeRepository{
+		local:  map[string]string{"refs/heads/main": "c1", "refs/tags/v2": "c0"},
+		remote: map[string]string{"refs/heads/main": "c1", "refs/tags/v2": "c0"},
+		fail:   map[string]bool{},
+	}
+}
+
+func (r *fakeRepository) SetReference(name, hash string) error {
+	r.local[name] = hash
+	return nil
+}
+
+func (r *fakeRepository) RemoveReference(name string) error {
+	delete(r.local, name)
+	return nil
+}
+
+func (r *fakeRepository) Push(ctx context.Context, refSpecs ...string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	var rejected error
+	for _, spec := range refSpecs {
+		parts := strings.SplitN(strings.TrimPrefix(spec, "+"), ":", 2)
+		if parts[0] != "" && r.fail[parts[1]] {
+			rejected = errors.New("rejected")
+		}
+	}
+	if rejected != nil && !r.partial {
+		return rejected
+	}
+	for _, spec := range refSpecs {
+		parts := strings.SplitN(strings.TrimPrefix(spec, "+"), ":", 2)
+		switch {
+		case parts[0] != "" && r.fail[parts[1]]:
+			continue
+		case parts[0] == "":
+			delete(r.remote, parts[1])
+		default:
+			r.remote[parts[1]] = r.local[parts[0]]
+		}
+	}
+	return rejected
+}
+
+func (r *fakeRepository) RemoteReferences(ctx context.Context) (map[string]string, error) {
+	return copyRefs(r.remote), nil
+}
+
+func newRelease(t *testing.T, repo *fakeRepository, file string) *Transaction {
+	tx, err := Begin(repo, nopLogger{}, file, "v2.1.0")
+	assert.NoError(t, err)
+
+	tag := Ref{Name: "refs/tags/v2.1.0", Hash: "c2"}
+	branch := Ref{Name: "refs/heads/main", Hash: "c2", Previous: "c1"}
+	alias := Ref{Name: "refs/tags/v2", Hash: "c2", Previous: "c0"}
+	tx.Add(Local, tag, branch)
+	tx.Add(Remote, tag, branch)
+	tx.Add(Local, alias)
+	tx.Add(Remote, alias)
+	return tx
+}
+
+func TestTransaction_Run(t *testing.T) {
+	dir, err := ioutil.TempDir("", "release-transaction")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "journal.json")
+
+	repo := newFakeRepository()
+	assert.NoError(t, newRelease(t, repo, file).Run(context.Background()))
+
+	expected := map[string]string{"refs/heads/main": "c2", "refs/tags/v2": "c2", "refs/tags/v2.1.0": "c2"}
+	assert.Equal(t, expected, repo.local)
+	assert.Equal(t, expected, repo.remote)
+	assert.NoFileExists(t, file)
+}
+
+func TestTransaction_Rollback(t *testing.T) {
+	dir, err := ioutil.TempDir("", "release-transaction")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "journal.json")
+
+	repo := newFakeRepository()
+	repo.fail["refs/tags/v2"] = true
+
+	assert.Error(t, newRelease(t, repo, file).Run(context.Background()))
+
+	assert.Equal(t, map[string]string{"refs/heads/main": "c1", "refs/tags/v2": "c0"}, repo.local)
+	// the pushed release commit isn't removed from the remote branch by force
+	assert.Equal(t, map[string]string{"refs/heads/main": "c2", "refs/tags/v2": "c0"}, repo.remote)
+	assert.NoFileExists(t, file)
+}
+
+func TestTransaction_RollbackConcurrentTag(t *testing.T) {
+	// a concurrent release pushed the tag, so the push is rejected, but deleting the tag would succeed
+	repo := newFakeRepository()
+	repo.remote["refs/tags/v2.1.0"] = "c9"
+	repo.fail["refs/tags/v2.1.0"] = true
+
+	assert.EqualError(t, newRelease(t, repo, "").Run(context.Background()),
+		"failed to push refs/tags/v2.1.0, refs/heads/main: rejected")
+	assert.Equal(t, map[string]string{"refs/heads/main": "c1", "refs/tags/v2": "c0"}, repo.local)
+	assert.Equal(t, map[string]string{"refs/heads/main": "c1", "refs/tags/v2": "c0", "refs/tags/v2.1.0": "c9"},
+		repo.remote)
+}
+
+func TestTransaction_RollbackPartialPush(t *testing.T) {
+	// the push isn't atomic, so the new tag is pushed, but a concurrent release rejects the new branch
+	repo := newFakeRepository()
+	repo.partial = true
+	repo.remote["refs/heads/release/2.1"] = "c9"
+	repo.fail["refs/heads/release/2.1"] = true
+
+	tx, err := Begin(repo, nopLogger{}, "", "v2.1.0")
+	assert.NoError(t, err)
+	tag := Ref{Name: "refs/tags/v2.1.0", Hash: "c2"}
+	branch := Ref{Name: "refs/heads/release/2.1", Hash: "c2"}
+	tx.Add(Local, tag, branch)
+	tx.Add(Remote, tag, branch)
+
+	assert.EqualError(t, tx.Run(context.Background()),
+		"failed to push refs/tags/v2.1.0, refs/heads/release/2.1: rejected")
+	assert.Equal(t, map[string]string{"refs/heads/main": "c1", "refs/tags/v2": "c0"}, repo.local)
+	assert.Equal(t, map[string]string{"refs/heads/main": "c1", "refs/tags/v2": "c0", "refs/heads/release/2.1": "c9"},
+		repo.remote)
+}
+
+func TestTransaction_RollbackChangedRemote(t *testing.T) {
+	repo := newFakeRepository()
+	tx, err := Begin(repo, nopLogger{}, "", "v2.1.0")
+	assert.NoError(t, err)
+
+	// somebody moves the pushed references before a later step fails
+	tx.SetHook(func(ctx context.Context, name string) error {
+		repo.remote["refs/tags/v2.1.0"] = "c9"
+		repo.remote["refs/tags/v2"] = "c9"
+		return errors.New("exit status 1")
+	})
+	tag := Ref{Name: "refs/tags/v2.1.0", Hash: "c2"}
+	alias := Ref{Name: "refs/tags/v2", Hash: "c2", Previous: "c0"}
+	tx.Add(Local, tag, alias)
+	tx.Add(Remote, tag, alias)
+	tx.AddHook("post-push")
+
+	assert.EqualError(t, tx.Run(context.Background()), "exit status 1")
+	assert.Equal(t, map[string]string{"refs/heads/main": "c1", "refs/tags/v2": "c0"}, repo.local)
+	assert.Equal(t, map[string]string{"refs/heads/main": "c1", "refs/tags/v2": "c9", "refs/tags/v2.1.0": "c9"},
+		repo.remote)
+}
+
+func TestTransaction_Recover(t *testing.T) {
+	dir, err := ioutil.TempDir("", "release-transaction")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "journal.json")
+
+	// the release is interrupted, after the tag was pushed
+	repo := newFakeRepository()
+	repo.fail["refs/tags/v2"] = true
+	tx := newRelease(t, repo, file)
+	tx.repo = &interruptedRepository{fakeRepository: repo}
+	assert.Error(t, runInterrupted(context.Background(), tx))
+	assert.FileExists(t, file)
+
+	_, err = Begin(repo, nopLogger{}, file, "v2.1.1")
+	assert.Equal(t, ErrUnfinished, err)
+
+	t.Run("finish", func(t *testing.T) {
+		repo := &fakeRepository{local: copyRefs(repo.local), remote: copyRefs(repo.remote), fail: map[string]bool{}}
+		journal := filepath.Join(dir, "finish.json")
+		data, err := ioutil.ReadFile(file)
+		assert.NoError(t, err)
+		assert.NoError(t, ioutil.WriteFile(journal, data, 0644))
+
+		tx, err := Open(repo, nopLogger{}, journal)
+		assert.NoError(t, err)
+		assert.NoError(t, tx.Run(context.Background()))
+		assert.Equal(t, "c2", repo.remote["refs/tags/v2"])
+		assert.Equal(t, "c2", repo.remote["refs/tags/v2.1.0"])
+		assert.NoFileExists(t, journal)
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		tx, err := Open(repo, nopLogger{}, file)
+		assert.NoError(t, err)
+		assert.NoError(t, tx.Rollback(context.Background()))
+
+		assert.Equal(t, map[string]string{"refs/heads/main": "c1", "refs/tags/v2": "c0"}, repo.local)
+		assert.Equal(t, map[string]string{"refs/heads/main": "c2", "refs/tags/v2": "c0"}, repo.remote)
+		assert.NoFileExists(t, file)
+	})
+}
+
+// interruptedRepository simulates a killed process by panicking on the first failing push.
+type interruptedRepository struct {
+	*fakeRepository
+}
+
+func (r *interruptedRepository) Push(ctx context.Context, refSpecs ...string) error {
+	if err := r.fakeRepository.Push(ctx, refSpecs...); err != nil {
+		panic(err)
+	}
+	return nil
+}
+
+func runInterrupted(ctx context.Context, tx *Transaction) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("interrupted")
+		}
+	}()
+	return tx.Run(ctx)
+}
+
+func copyRefs(refs map[string]string) map[string]string {
+	var c = make(map[string]string, len(refs))
+	for name, hash := range refs {
+		c[name] = hash
+	}
+	return c
+}
+
+func TestTransaction_Hook(t *testing.T) {
+	repo := newFakeRepository()
+	tx, err := Begin(repo, nopLogger{}, "", "v2.1.0")
+	assert.NoError(t, err)
+
+	var hooks []string
+	tx.SetHook(func(ctx context.Context, name string) error {
+		hooks = append(hooks, name)
+		if name == "post-tag" {
+			return errors.New("exit status 1")
+		}
+		return nil
+	})
+
+	tag := Ref{Name: "refs/tags/v2.1.0", Hash: "c2"}
+	tx.AddHook("pre-tag")
+	tx.Add(Local, tag)
+	tx.AddHook("post-tag")
+	tx.Add(Remote, tag)
+
+	assert.EqualError(t, tx.Run(context.Background()), "exit status 1")
+	assert.Equal(t, []string{"pre-tag", "post-tag"}, hooks)
+	assert.NotContains(t, repo.local, tag.Name)
+	assert.NotContains(t, repo.remote, tag.Name)
+}
+
+func TestTransaction_Interrupt(t *testing.T) {
+	repo := newFakeRepository()
+	tx, err := Begin(repo, nopLogger{}, "", "v2.1.0")
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	tx.SetHook(func(ctx context.Context, name string) error {
+		// the hook is killed by the interrupt
+		cancel()
+		return ctx.Err()
+	})
+
+	tag := Ref{Name: "refs/tags/v2.1.0", Hash: "c2"}
+	tx.Add(Local, tag)
+	tx.Add(Remote, tag)
+	tx.AddHook("post-push")
+
+	assert.Equal(t, context.Canceled, tx.Run(ctx))
+	assert.NotContains(t, repo.local, tag.Name)
+	assert.NotContains(t, repo.remote, tag.Name, "the rollback isn't canceled")
+}
+
+// nopLogger drops all log entries.
+type nopLogger struct{}
+
+func (l nopLogger) WithFields(Fields) Logger                { return l }
+func (nopLogger) Debugf(format string, args ...interface{}) {}
+func (nopLogger) Infof(format string, args ...interface{})  {}
+func (nopLogger) Warnf(format string, args ...interface{})  {}
+func (nopLogger) Errorf(format string, args ...interface{}) {}
@@ -0,0 +1,243 @@
+// Package tui implements the interactive prompts of the release tool: a menu, whose options are chosen with the arrow
+// keys, a confirmation and the editing of a text in the editor of the user.
+package tui
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// ErrCanceled is returned if the user cancels a prompt.
+var ErrCanceled = errors.New("canceled by the user")
+
+// ErrNoTerminal is returned if the prompts can't be shown, because the input or output isn't a terminal.
+var ErrNoTerminal = errors.New("the input or output isn't a terminal")
+
+// Key is a pressed key.
+type Key int
+
+const (
+	// Other is a key without special meaning.
+	Other Key = iota
+	// Up is the up arrow or k.
+	Up
+	// Down is the down arrow or j.
+	Down
+	// Enter confirms the selection.
+	Enter
+	// Cancel is Ctrl+C, Escape or q.
+	Cancel
+	// Edit is e.
+	Edit
+)
+
+// ReadKey reads the next key of the input in raw mode.
+func ReadKey(in *bufio.Reader) (Key, error) {
+	b, err := in.ReadByte()
+	if err != nil {
+		return Other, err
+	}
+
+	switch b {
+	case '\r', '\n':
+		return Enter, nil
+	case 3, 'q':
+		return Cancel, nil
+	case 'k':
+		return Up, nil
+	case 'j':
+		return Down, nil
+	case 'e':
+		return Edit, nil
+	case 0x1b:
+		// a lone escape cancels, an escape sequence like ESC [ A is an arrow key
+		if in.Buffered() == 0 {
+			return Cancel, nil
+		}
+		if next, _ := in.ReadByte(); next != '[' && next != 'O' {
+			return Other, nil
+		}
+		switch code, _ := in.ReadByte(); code {
+		case 'A':
+			return Up, nil
+		case 'B':
+			return Down, nil
+		}
+	}
+	return Other, nil
+}
+
+// Option is an option of a menu.
+type Option struct {
+	// Label names the option.
+	Label string
+	// Detail is shown next to the label.
+	Detail string
+}
+
+// Menu is a list of options, which are chosen with the arrow keys.
+type Menu struct {
+	// Title is shown above the options.
+	Title string
+	// Options are the choices.
+	Options []Option
+	// Selected is the index of the initially selected option.
+	Selected int
+	// Help explains the keys below the options.
+	Help string
+}
+
+// Choose shows the menu and returns the index of the chosen option and the key, which confirmed the choice: Enter or
+// Edit. The input must be in raw mode, so the lines end with \r\n.
+func Choose(in *bufio.Reader, out io.Writer, m Menu) (int, Key, error) {
+	selected := m.Selected
+	lines := m.render(out, selected, 0)
+	for {
+		key, err := ReadKey(in)
+		if err == io.EOF {
+			return 0, Other, ErrCanceled
+		}
+		if err != nil {
+			return 0, Other, err
+		}
+
+		switch key {
+		case Up:
+			if selected > 0 {
+				selected--
+			}
+		case Down:
+			if selected < len(m.Options)-1 {
+				selected++
+			}
+		case Enter, Edit:
+			return selected, key, nil
+		case Cancel:
+			return 0, Other, ErrCanceled
+		default:
+			continue
+		}
+		lines = m.render(out, selected, lines)
+	}
+}
+
+// render writes the menu with the selected option. The previous rendering with the given number of lines is
+// overwritten. It returns the number of written lines.
+func (m Menu) render(out io.Writer, selected, previous int) int {
+	var b strings.Builder
+	if previous > 0 {
+		// move up to the first line and clear the screen below
+		fmt.Fprintf(&b, "\x1b[%dA\r\x1b[J", previous)
+	}
+
+	lines := len(m.Options)
+	if m.Title != "" {
+		fmt.Fprintf(&b, "%v\r\n", m.Title)
+		lines++
+	}
+	for i, o := range m.Options {
+		cursor := "  "
+		if i == selected {
+			cursor = "> "
+		}
+		fmt.Fprintf(&b, "%v%-8v %v\r\n", cursor, o.Label, o.Detail)
+	}
+	if m.Help != "" {
+		fmt.Fprintf(&b, "%v\r\n", m.Help)
+		lines++
+	}
+	_, _ = io.WriteString(out, b.String())
+	return lines
+}
+
+// Confirm asks the yes or no question and reports whether the answer is yes. The input must be in raw mode.
+func Confirm(in *bufio.Reader, out io.Writer, question string) (bool, error) {
+	fmt.Fprintf(out, "%v [y/N] ", question)
+	b, err := in.ReadByte()
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+	yes := b == 'y' || b == 'Y'
+	if yes {
+		fmt.Fprint(out, "y")
+	}
+	fmt.Fprint(out, "\r\n")
+	return yes, nil
+}
+
+// EditText opens the text in the editor of $VISUAL or $EDITOR, which defaults to vi, and returns the edited text.
+// Lines starting with # are removed. The input must not be in raw mode.
+func EditText(text string) (string, error) {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	f, err := ioutil.TempFile("", "release-*.txt")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(text); err != nil {
+		f.Close()
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+
+	// the editor command may have arguments like "code --wait"
+	cmd := exec.Command("sh", "-c", editor+` "$1"`, "editor", f.Name())
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stderr, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("the editor %v failed: %w", editor, err)
+	}
+
+	data, err := ioutil.ReadFile(f.Name())
+	if err != nil {
+		return "", err
+	}
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n")), nil
+}
+
+// Terminal is the terminal of the prompts.
+type Terminal struct {
+	In  *os.File
+	Out *os.File
+}
+
+// Check returns ErrNoTerminal if the input or the output isn't a terminal.
+func (t Terminal) Check() error {
+	if !terminal.IsTerminal(int(t.In.Fd())) || !terminal.IsTerminal(int(t.Out.Fd())) {
+		return ErrNoTerminal
+	}
+	return nil
+}
+
+// Raw puts the input into raw mode and returns the function, which restores the previous mode.
+func (t Terminal) Raw() (func(), error) {
+	state, err := terminal.MakeRaw(int(t.In.Fd()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to enter the raw mode of the terminal: %w", err)
+	}
+	return func() {
+		_ = terminal.Restore(int(t.In.Fd()), state)
+	}, nil
+}
@@ -0,0 +1,96 @@
+package tui
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadKey(t *testing.T) {
+	in := bufio.NewReader(strings.NewReader("\x1b[A\x1b[Bkj\re\x03qx\x1bOA\x1b"))
+	var keys []Key
+	for {
+		key, err := ReadKey(in)
+		if err != nil {
+			break
+		}
+		keys = append(keys, key)
+	}
+	assert.Equal(t, []Key{Up, Down, Up, Down, Enter, Edit, Cancel, Cancel, Other, Up, Cancel}, keys)
+}
+
+func TestChoose(t *testing.T) {
+	menu := Menu{
+		Title:    "Release",
+		Options:  []Option{{Label: "major", Detail: "v2.0.0"}, {Label: "minor", Detail: "v1.4.0"}, {Label: "patch", Detail: "v1.3.1"}},
+		Selected: 2,
+	}
+
+	tt := []struct {
+		name     string
+		input    string
+		selected int
+		key      Key
+		err      error
+	}{
+		{"default", "\r", 2, Enter, nil},
+		{"up", "\x1b[A\x1b[Ax\r", 0, Enter, nil},
+		{"bounds", "kkkkjjjjjj\r", 2, Enter, nil},
+		{"edit", "ke", 1, Edit, nil},
+		{"cancel", "kq", 0, Other, ErrCanceled},
+		{"end of input", "k", 0, Other, ErrCanceled},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+			selected, key, err := Choose(bufio.NewReader(strings.NewReader(tc.input)), &out, menu)
+			assert.Equal(t, tc.err, err)
+			assert.Equal(t, tc.selected, selected)
+			assert.Equal(t, tc.key, key)
+		})
+	}
+}
+
+func TestMenu_render(t *testing.T) {
+	menu := Menu{
+		Title:   "Release",
+		Options: []Option{{Label: "major", Detail: "v2.0.0"}, {Label: "minor", Detail: "v1.4.0"}},
+		Help:    "enter: release",
+	}
+
+	var out bytes.Buffer
+	assert.Equal(t, 4, menu.render(&out, 1, 0))
+	assert.Equal(t, "Release\r\n  major    v2.0.0\r\n> minor    v1.4.0\r\nenter: release\r\n", out.String())
+
+	out.Reset()
+	menu.render(&out, 0, 4)
+	assert.Equal(t, "\x1b[4A\r\x1b[JRelease\r\n> major    v2.0.0\r\n  minor    v1.4.0\r\nenter: release\r\n", out.String())
+}
+
+func TestConfirm(t *testing.T) {
+	var out bytes.Buffer
+	yes, err := Confirm(bufio.NewReader(strings.NewReader("y")), &out, "Release v1.4.0?")
+	assert.NoError(t, err)
+	assert.True(t, yes)
+	assert.Equal(t, "Release v1.4.0? [y/N] y\r\n", out.String())
+
+	yes, err = Confirm(bufio.NewReader(strings.NewReader("\r")), &out, "Release v1.4.0?")
+	assert.NoError(t, err)
+	assert.False(t, yes)
+}
+
+func TestEditText(t *testing.T) {
+	// the editor appends a line to the file of its argument
+	visual := os.Getenv("VISUAL")
+	defer os.Setenv("VISUAL", visual)
+	assert.NoError(t, os.Setenv("VISUAL", `sh -c 'echo "# comment" >> "$1"; echo "Second line" >> "$1"' editor`))
+
+	text, err := EditText("Release v1.4.0\n\n")
+	assert.NoError(t, err)
+	assert.Equal(t, "Release v1.4.0\n\nSecond line", text)
+}
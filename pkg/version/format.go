@@ -0,0 +1,142 @@
+package version
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// RegExPatternPreLabel is the RegEX of a valid pre-release label.
+const RegExPatternPreLabel = `^[A-Za-z][0-9A-Za-z\-]*$`
+
+// RegExPatternDefaultTag is the RegEX of the tags of the default format. The version is the end of the tag or of its
+// last path segment and the only pre-release label is RC.
+const RegExPatternDefaultTag = `(?:^|/)v?(\d+)\.(\d+)\.(\d+)(?:-RC\.?(\d+))?$`
+
+// LatestAlias is the alias tag of the latest final release.
+const LatestAlias = "latest"
+
+// DefaultFormat is the notation of the version tags like v1.2.3 and v1.2.3-RC.4.
+var DefaultFormat = Format{Prefix: "v", PreLabel: "RC"}
+
+// Format is the notation of version tags.
+type Format struct {
+	// Prefix precedes the version numbers of a tag, e.g. v or api/v for a module in a subdirectory.
+	Prefix string
+	// PreLabel is the label of the pre-release versions, e.g. RC or beta.
+	PreLabel string
+}
+
+// Validate returns an error if the pre-release label isn't a valid semantic version identifier.
+func (f Format) Validate() error {
+	if !regexp.MustCompile(RegExPatternPreLabel).MatchString(f.PreLabel) {
+		return fmt.Errorf("invalid pre-release label %q", f.PreLabel)
+	}
+	return nil
+}
+
+// Tag returns the tag name of the version.
+func (f Format) Tag(v Version) string {
+	tag := fmt.Sprintf("%v%v.%v.%v", f.Prefix, v[Major], v[Minor], v[Patch])
+	if v.IsReleaseCandidate() {
+		tag += fmt.Sprintf("-%v.%v", f.PreLabel, v[Pre])
+	}
+	return tag
+}
+
+// Version returns the semantic version of the version with the pre-release label of the format, e.g. v1.2.3-beta.4,
+// which is the version of a Go module and of the releases in the output. Unlike the tag, it has no prefix.
+func (f Format) Version(v Version) string {
+	version := fmt.Sprintf("v%v.%v.%v", v[Major], v[Minor], v[Patch])
+	if v.IsReleaseCandidate() {
+		version += fmt.Sprintf("-%v.%v", f.PreLabel, v[Pre])
+	}
+	return version
+}
+
+// Parse parses the version of a tag. The tag may be given as tag name, reference name or reference string. The
+// default format accepts the versions at the end of any tag, any other format only tags with its prefix and
+// pre-release label. Both refuse tags with another pre-release label, e.g. the tags of a release channel. Unlike New,
+// it returns an error if the tag isn't a version.
+func (f Format) Parse(tag string) (Version, error) {
+	var r []string
+	if f == DefaultFormat {
+		if r = regexp.MustCompile(RegExPatternDefaultTag).FindStringSubmatch(tag); len(r) == 0 {
+			return nil, fmt.Errorf("%q isn't a version like v1.2.3 or v1.2.3-RC.4", tag)
+		}
+	} else {
+		if i := strings.LastIndex(tag, "refs/tags/"); i >= 0 {
+			tag = tag[i+len("refs/tags/"):]
+		}
+
+		r = regexp.
+			MustCompile(`^` + regexp.QuoteMeta(f.Prefix) + `(\d+)\.(\d+)\.(\d+)(?:-` + regexp.QuoteMeta(f.PreLabel) + `\.?(\d+))?$`).
+			FindStringSubmatch(tag)
+		if len(r) == 0 {
+			return nil, fmt.Errorf("the tag %q doesn't match the format %v", tag, f)
+		}
+	}
+
+	var version = make(Version, 4)
+	for i := range version {
+		if r[i+1] == "" {
+			continue
+		}
+		number, err := strconv.ParseUint(r[i+1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		version[i] = uint(number)
+	}
+
+	return version, nil
+}
+
+// IsAlias reports whether the tag is a floating alias tag like v2, v2.3 or latest. The tag may be given as tag name,
+// reference name or reference string.
+func (f Format) IsAlias(tag string) bool {
+	if i := strings.LastIndex(tag, "refs/tags/"); i >= 0 {
+		tag = tag[i+len("refs/tags/"):]
+	}
+
+	return tag == LatestAlias ||
+		regexp.MustCompile(`^`+regexp.QuoteMeta(f.Prefix)+`\d+(?:\.\d+)?$`).MatchString(tag)
+}
+
+// Aliases returns the floating alias tags of the major and minor line of the version, e.g. v2 and v2.3, and the latest
+// alias if latest is true. Pre-releases have no aliases and an alias is left out if an existing final version of its
+// line is newer than the version.
+func (f Format) Aliases(v Version, existing Versions, latest bool) []string {
+	if v.IsReleaseCandidate() {
+		return nil
+	}
+
+	majorLine, minorLine, newestMajor, newestMinor := VersionLine(v, false), VersionLine(v, true), true, true
+	for _, e := range existing {
+		if e.IsReleaseCandidate() || Compare(e, v) <= 0 {
+			continue
+		}
+		newestMajor = newestMajor && !majorLine.Contains(e)
+		newestMinor = newestMinor && !minorLine.Contains(e)
+		latest = false
+	}
+
+	var aliases []string
+	if newestMajor {
+		aliases = append(aliases, fmt.Sprintf("%v%v", f.Prefix, v[Major]))
+	}
+	if newestMinor {
+		aliases = append(aliases, fmt.Sprintf("%v%v.%v", f.Prefix, v[Major], v[Minor]))
+	}
+	if latest {
+		aliases = append(aliases, LatestAlias)
+	}
+
+	return aliases
+}
+
+// String returns the format as pattern.
+func (f Format) String() string {
+	return fmt.Sprintf("%vX.Y.Z[-%v.N]", f.Prefix, f.PreLabel)
+}
@@ -0,0 +1,132 @@
+package version
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat_Tag(t *testing.T) {
+	tt := []struct {
+		format   Format
+		version  Version
+		expected string
+	}{
+		{DefaultFormat, Version{1, 2, 3, 0}, "v1.2.3"},
+		{DefaultFormat, Version{1, 2, 3, 4}, "v1.2.3-RC.4"},
+		{Format{Prefix: "api/v", PreLabel: "beta"}, Version{1, 2, 3, 4}, "api/v1.2.3-beta.4"},
+		{Format{PreLabel: "rc"}, Version{1, 2, 3, 0}, "1.2.3"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.expected, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.format.Tag(tc.version))
+		})
+	}
+
+	assert.Equal(t, DefaultFormat.Tag(Version{1, 2, 3, 4}), Version{1, 2, 3, 4}.String())
+}
+
+func TestFormat_Version(t *testing.T) {
+	tt := []struct {
+		format   Format
+		version  Version
+		expected string
+	}{
+		{DefaultFormat, Version{1, 2, 3, 0}, "v1.2.3"},
+		{DefaultFormat, Version{1, 2, 3, 4}, "v1.2.3-RC.4"},
+		{Format{Prefix: "api/v", PreLabel: "beta"}, Version{1, 2, 3, 4}, "v1.2.3-beta.4"},
+		{Format{PreLabel: "rc"}, Version{1, 2, 3, 0}, "v1.2.3"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.expected, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.format.Version(tc.version))
+		})
+	}
+}
+
+func TestFormat_Parse(t *testing.T) {
+	custom := Format{Prefix: "api/v", PreLabel: "beta"}
+
+	tt := []struct {
+		format   Format
+		tag      string
+		expected Version
+		fails    bool
+	}{
+		{DefaultFormat, "x/y/z/v1.2.3-RC4", Version{1, 2, 3, 4}, false},
+		{DefaultFormat, "v1.2.3-RC.4", Version{1, 2, 3, 4}, false},
+		{DefaultFormat, "refs/tags/1.0.0", Version{1, 0, 0, 0}, false},
+		{DefaultFormat, "0123abcd refs/tags/v1.0.0", Version{1, 0, 0, 0}, false},
+		{DefaultFormat, "no version", nil, true},
+		{DefaultFormat, "", nil, true},
+		{DefaultFormat, "1.2.3-RC", nil, true},
+		{DefaultFormat, "1.2.3-RCy", nil, true},
+		{DefaultFormat, "v1.3.0-beta.1", nil, true},
+		{DefaultFormat, "latest", nil, true},
+		{DefaultFormat, "deploy-prod", nil, true},
+		{DefaultFormat, "x1.2.3", nil, true},
+		{custom, "api/v1.2.3", Version{1, 2, 3, 0}, false},
+		{custom, "api/v1.2.3-beta.4", Version{1, 2, 3, 4}, false},
+		{custom, "0123abcd refs/tags/api/v1.2.3-beta4", Version{1, 2, 3, 4}, false},
+		{custom, "v1.2.3", nil, true},
+		{custom, "api/v1.2.3-RC.4", nil, true},
+		{custom, "x/api/v1.2.3", nil, true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.tag, func(t *testing.T) {
+			v, err := tc.format.Parse(tc.tag)
+			if tc.fails {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, v)
+		})
+	}
+}
+
+func TestFormat_Validate(t *testing.T) {
+	assert.NoError(t, DefaultFormat.Validate())
+	assert.NoError(t, Format{PreLabel: "beta-1"}.Validate())
+	assert.Error(t, Format{PreLabel: ""}.Validate())
+	assert.Error(t, Format{PreLabel: "1rc"}.Validate())
+	assert.Error(t, Format{PreLabel: "r.c"}.Validate())
+}
+
+func TestFormat_IsAlias(t *testing.T) {
+	custom := Format{Prefix: "api/v", PreLabel: "beta"}
+
+	assert.True(t, DefaultFormat.IsAlias("v2"))
+	assert.True(t, DefaultFormat.IsAlias("0123abcd refs/tags/v2.3"))
+	assert.True(t, DefaultFormat.IsAlias("latest"))
+	assert.True(t, custom.IsAlias("api/v2"))
+	assert.False(t, DefaultFormat.IsAlias("v2.3.4"))
+	assert.False(t, DefaultFormat.IsAlias("api/v2"))
+	assert.False(t, custom.IsAlias("v2"))
+}
+
+func TestFormat_Aliases(t *testing.T) {
+	existing := Versions{{2, 3, 4, 0}, {2, 4, 0, 0}, {2, 5, 0, 1}, {3, 0, 0, 0}}
+
+	tt := []struct {
+		name     string
+		version  Version
+		latest   bool
+		expected []string
+	}{
+		{"newest", Version{3, 0, 1, 0}, true, []string{"v3", "v3.0", "latest"}},
+		{"without latest", Version{3, 0, 1, 0}, false, []string{"v3", "v3.0"}},
+		{"newest of major line", Version{2, 4, 1, 0}, true, []string{"v2", "v2.4"}},
+		{"maintenance", Version{2, 3, 5, 0}, true, []string{"v2.3"}},
+		{"pre-release", Version{3, 1, 0, 1}, true, nil},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, DefaultFormat.Aliases(tc.version, existing, tc.latest))
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package version
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// RegExPatternLineString is the RegEX to parse a release line like v2 or v2.4.
+const RegExPatternLineString = `^v?(\d+)(?:\.(\d+))?$`
+
+// DefaultBranchTemplate is the default name template of maintenance branches.
+const DefaultBranchTemplate = "release/{major}.{minor}"
+
+// Line is a release line of all versions with the same major version or the same major and minor version.
+type Line struct {
+	Major, Minor uint
+	// HasMinor reports whether the line is limited to a minor version.
+	HasMinor bool
+}
+
+// ParseLine parses a release line like v2 or v2.4.
+func ParseLine(l string) (Line, error) {
+	r := regexp.MustCompile(RegExPatternLineString).FindStringSubmatch(l)
+	if len(r) == 0 {
+		return Line{}, fmt.Errorf("invalid release line %q, expected vX or vX.Y", l)
+	}
+
+	major, err := strconv.ParseUint(r[1], 10, 64)
+	if err != nil {
+		return Line{}, err
+	}
+	line := Line{Major: uint(major)}
+
+	if r[2] != "" {
+		minor, err := strconv.ParseUint(r[2], 10, 64)
+		if err != nil {
+			return Line{}, err
+		}
+		line.Minor, line.HasMinor = uint(minor), true
+	}
+
+	return line, nil
+}
+
+// Contains reports whether the version belongs to the release line.
+func (l Line) Contains(v Version) bool {
+	return v[Major] == l.Major && (!l.HasMinor || v[Minor] == l.Minor)
+}
+
+// String returns the release line as string.
+func (l Line) String() string {
+	if l.HasMinor {
+		return fmt.Sprintf("v%v.%v", l.Major, l.Minor)
+	}
+	return fmt.Sprintf("v%v", l.Major)
+}
+
+// Check returns an error if the next version doesn't belong to the release line, already exists or is older than an
+// existing version of the line, so that releasing it would skip over the newer version.
+func (l Line) Check(next Version, existing Versions) error {
+	if !l.Contains(next) {
+		return fmt.Errorf("the version %v doesn't belong to the release line %v", next, l)
+	}
+
+	for _, v := range existing {
+		if !l.Contains(v) {
+			continue
+		}
+		switch Compare(v, next) {
+		case 0:
+			return fmt.Errorf("the version %v already exists", next)
+		case 1:
+			return fmt.Errorf("the version %v is older than the existing version %v of the release line %v", next, v, l)
+		}
+	}
+
+	return nil
+}
+
+// VersionLine returns the release line of the version. The line is limited to the minor version if minor is true.
+func VersionLine(v Version, minor bool) Line {
+	return Line{Major: v[Major], Minor: v[Minor], HasMinor: minor}
+}
+
+// ValidateBranchTemplate returns an error if the name template of maintenance branches doesn't contain the {major}
+// placeholder or contains unknown placeholders.
+func ValidateBranchTemplate(template string) error {
+	if !strings.Contains(template, "{major}") {
+		return fmt.Errorf("invalid branch template %q, expected a {major} placeholder", template)
+	}
+
+	rest := strings.NewReplacer("{major}", "", "{minor}", "").Replace(template)
+	if strings.ContainsAny(rest, "{}") {
+		return fmt.Errorf("invalid branch template %q, expected only {major} and {minor} placeholders", template)
+	}
+
+	return nil
+}
+
+// Branch returns the name of the maintenance branch of the release line. The placeholders {major} and {minor} of the
+// template are replaced by the numbers of the line.
+func (l Line) Branch(template string) string {
+	return strings.NewReplacer(
+		"{major}", strconv.FormatUint(uint64(l.Major), 10),
+		"{minor}", strconv.FormatUint(uint64(l.Minor), 10),
+	).Replace(template)
+}
+
+// TemplateLine returns the release line of a maintenance branch whose name matches the template. It returns false if
+// the branch doesn't match.
+func TemplateLine(template, branch string) (Line, bool) {
+	pattern := strings.NewReplacer(
+		regexp.QuoteMeta("{major}"), `(?P<major>\d+)`,
+		regexp.QuoteMeta("{minor}"), `(?P<minor>\d+)`,
+	).Replace(regexp.QuoteMeta(template))
+
+	re, err := regexp.Compile("^" + pattern + "$")
+	if err != nil {
+		return Line{}, false
+	}
+	r := re.FindStringSubmatch(branch)
+	if len(r) == 0 {
+		return Line{}, false
+	}
+
+	var line Line
+	for i, name := range re.SubexpNames() {
+		number, err := strconv.ParseUint(r[i], 10, 64)
+		if err != nil {
+			continue
+		}
+		switch name {
+		case "major":
+			line.Major = uint(number)
+		case "minor":
+			line.Minor, line.HasMinor = uint(number), true
+		}
+	}
+
+	return line, true
+}
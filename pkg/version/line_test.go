@@ -0,0 +1,121 @@
+package version
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLine(t *testing.T) {
+	tt := []struct {
+		value    string
+		expected Line
+		fails    bool
+	}{
+		{"v2", Line{Major: 2}, false},
+		{"2.4", Line{Major: 2, Minor: 4, HasMinor: true}, false},
+		{"v0.10", Line{Major: 0, Minor: 10, HasMinor: true}, false},
+		{"v2.4.1", Line{}, true},
+		{"release/2.4", Line{}, true},
+		{"", Line{}, true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.value, func(t *testing.T) {
+			line, err := ParseLine(tc.value)
+			if tc.fails {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, line)
+		})
+	}
+}
+
+func TestLine_Contains(t *testing.T) {
+	tt := []struct {
+		line     string
+		version  Version
+		expected bool
+	}{
+		{"v2", Version{2, 0, 0, 0}, true},
+		{"v2", Version{2, 4, 7, 1}, true},
+		{"v2", Version{3, 0, 0, 0}, false},
+		{"v2.4", Version{2, 4, 7, 0}, true},
+		{"v2.4", Version{2, 5, 0, 0}, false},
+		{"v2.4", Version{1, 4, 0, 0}, false},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.line+" "+tc.version.String(), func(t *testing.T) {
+			line, err := ParseLine(tc.line)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, line.Contains(tc.version))
+			assert.Equal(t, tc.line, line.String())
+		})
+	}
+}
+
+func TestLine_Check(t *testing.T) {
+	line := Line{Major: 2, Minor: 4, HasMinor: true}
+	existing := Versions{{2, 4, 6, 0}, {2, 4, 7, 0}, {2, 5, 0, 0}, {3, 0, 0, 0}}
+
+	tt := []struct {
+		name  string
+		next  Version
+		fails bool
+	}{
+		{"next patch", Version{2, 4, 8, 0}, false},
+		{"next release candidate", Version{2, 4, 8, 1}, false},
+		{"other line", Version{2, 5, 0, 0}, true},
+		{"existing", Version{2, 4, 7, 0}, true},
+		{"skips newer", Version{2, 4, 7, 1}, true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := line.Check(tc.next, existing)
+			if tc.fails {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestValidateBranchTemplate(t *testing.T) {
+	assert.NoError(t, ValidateBranchTemplate(DefaultBranchTemplate))
+	assert.NoError(t, ValidateBranchTemplate("maintenance/v{major}"))
+	assert.Error(t, ValidateBranchTemplate("release/{minor}"))
+	assert.Error(t, ValidateBranchTemplate("release/{major}.{patch}"))
+}
+
+func TestLine_Branch(t *testing.T) {
+	assert.Equal(t, "release/2.4", Line{Major: 2, Minor: 4, HasMinor: true}.Branch(DefaultBranchTemplate))
+	assert.Equal(t, "maintenance/v2", Line{Major: 2}.Branch("maintenance/v{major}"))
+}
+
+func TestTemplateLine(t *testing.T) {
+	tt := []struct {
+		template, branch string
+		expected         Line
+		ok               bool
+	}{
+		{DefaultBranchTemplate, "release/2.4", Line{Major: 2, Minor: 4, HasMinor: true}, true},
+		{DefaultBranchTemplate, "release/2", Line{}, false},
+		{DefaultBranchTemplate, "hotfix/2.4", Line{}, false},
+		{"maintenance/v{major}", "maintenance/v3", Line{Major: 3}, true},
+		{"v{major}.{minor}.x", "v1.12.x", Line{Major: 1, Minor: 12, HasMinor: true}, true},
+		{"v{major}.{minor}.x", "v1a12.x", Line{}, false},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.branch, func(t *testing.T) {
+			line, ok := TemplateLine(tc.template, tc.branch)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.expected, line)
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package version
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// PseudoTimeFormat is the layout of the commit time in a pseudo-version.
+	PseudoTimeFormat = "20060102150405"
+	// PseudoHashLength is the length of the abbreviated commit hash in a pseudo-version.
+	PseudoHashLength = 12
+	// DescribeHashLength is the length of the abbreviated commit hash in a git-describe version.
+	DescribeHashLength = 7
+)
+
+// Pseudo returns the Go pseudo-version of a commit following the rules of the go command. The base is the latest
+// version tag reachable from the commit and may be nil if no such tag exists. The versions have the pre-release label
+// of the format, but no tag prefix like the versions of a Go module.
+//
+//	no base:     v0.0.0-yyyymmddhhmmss-abcdefabcdef
+//	release:     vX.Y.(Z+1)-0.yyyymmddhhmmss-abcdefabcdef
+//	pre-release: vX.Y.Z-RC.N.0.yyyymmddhhmmss-abcdefabcdef
+func Pseudo(format Format, base Version, commitTime time.Time, hash string) string {
+	suffix := fmt.Sprintf("%v-%v", commitTime.UTC().Format(PseudoTimeFormat), abbreviate(hash, PseudoHashLength))
+
+	switch {
+	case len(base) == 0:
+		return "v0.0.0-" + suffix
+	case base.IsReleaseCandidate():
+		return format.Version(base) + ".0." + suffix
+	default:
+		next := make(Version, len(base))
+		copy(next, base)
+		next.increaseVersion(Patch)
+		return format.Version(next) + "-0." + suffix
+	}
+}
+
+// Describe returns the version of a commit in the notation of git describe, e.g. v1.2.3-5-gabcdef1. The tag is the
+// latest version tag reachable from the commit and count the number of commits since the tag. A tagged commit is
+// described by its tag only. Without a tag the tag of the version 0.0.0 in the format is used.
+func Describe(format Format, tag string, count int, hash string) string {
+	if tag == "" {
+		tag = format.Tag(Version{0, 0, 0, 0})
+	}
+	if count == 0 {
+		return tag
+	}
+	return fmt.Sprintf("%v-%d-g%v", tag, count, abbreviate(hash, DescribeHashLength))
+}
+
+func abbreviate(hash string, length int) string {
+	if len(hash) > length {
+		return hash[:length]
+	}
+	return hash
+}
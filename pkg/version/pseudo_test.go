@@ -0,0 +1,62 @@
+package version
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const hash = "abcdef1234567890abcdef1234567890abcdef12"
+
+func TestPseudo(t *testing.T) {
+	commitTime := time.Date(2020, 3, 4, 5, 6, 7, 0, time.FixedZone("CET", 3600))
+
+	tt := []struct {
+		format   Format
+		base     string
+		expected string
+	}{
+		{DefaultFormat, "", "v0.0.0-20200304040607-abcdef123456"},
+		{DefaultFormat, "v1.2.3", "v1.2.4-0.20200304040607-abcdef123456"},
+		{DefaultFormat, "v1.2.3-RC.4", "v1.2.3-RC.4.0.20200304040607-abcdef123456"},
+		{Format{Prefix: "api/v", PreLabel: "beta"}, "v1.2.3-RC.4", "v1.2.3-beta.4.0.20200304040607-abcdef123456"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.expected, func(t *testing.T) {
+			var base Version
+			if tc.base != "" {
+				var err error
+				base, err = New(tc.base)
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, tc.expected, Pseudo(tc.format, base, commitTime, hash))
+			if base != nil {
+				assert.Equal(t, tc.base, base.String(), "the base version must not be changed")
+			}
+		})
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	tt := []struct {
+		format   Format
+		tag      string
+		count    int
+		expected string
+	}{
+		{DefaultFormat, "", 3, "v0.0.0-3-gabcdef1"},
+		{DefaultFormat, "v1.2.3", 0, "v1.2.3"},
+		{DefaultFormat, "v1.2.3", 5, "v1.2.3-5-gabcdef1"},
+		{Format{Prefix: "api/v", PreLabel: "RC"}, "api/v1.2.3-RC.1", 1, "api/v1.2.3-RC.1-1-gabcdef1"},
+		{Format{Prefix: "", PreLabel: "RC"}, "", 3, "0.0.0-3-gabcdef1"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.expected, func(t *testing.T) {
+			assert.Equal(t, tc.expected, Describe(tc.format, tc.tag, tc.count, hash))
+		})
+	}
+}
@@ -20,6 +20,16 @@ const (
 	RegExPatternVersionString = `((\d+)\.(\d+)\.(\d+))(?:-RC\.?([\dA-Za-z\-]+(?:\.[\dA-Za-z\-]+)*))?`
 )
 
+var partNames = []string{"major", "minor", "patch", "pre"}
+
+// PartName returns the name of the version part at the given position, e.g. "minor" for Minor.
+func PartName(part int) string {
+	if part < Major || part > Pre {
+		return ""
+	}
+	return partNames[part]
+}
+
 // Version is the abstraction of the version.
 type Version []uint
 
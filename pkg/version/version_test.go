@@ -117,3 +117,25 @@ func TestVersion_String(t *testing.T) {
 		})
 	}
 }
+
+func TestPartName(t *testing.T) {
+	tt := []struct {
+		part     int
+		expected string
+	}{
+		{Major, "major"},
+		{Minor, "minor"},
+		{Patch, "patch"},
+		{Pre, "pre"},
+		{-1, ""},
+		{4, ""},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.expected, func(t *testing.T) {
+			if actual := PartName(tc.part); tc.expected != actual {
+				t.Errorf("The elements are not equal: \n actual: \t %v \n expected: \t %v", actual, tc.expected)
+			}
+		})
+	}
+}
@@ -28,3 +28,29 @@ func (versions Versions) Less(i, j int) bool {
 func (versions Versions) Swap(i, j int) {
 	versions[i], versions[j] = versions[j], versions[i]
 }
+
+// Compare returns -1 if the version a is lower than b, 1 if a is greater than b and 0 if both are equal.
+func Compare(a, b Version) int {
+	switch {
+	case DifferingPart(a, b) < 0:
+		// Less reports equal versions as lower
+		return 0
+	case Versions{a, b}.Less(0, 1):
+		return -1
+	case Versions{b, a}.Less(0, 1):
+		return 1
+	default:
+		return 0
+	}
+}
+
+// DifferingPart returns the first version part (Major, Minor, Patch or Pre) which differs between the versions a and b.
+// It returns -1 if both versions are equal.
+func DifferingPart(a, b Version) int {
+	for part := Major; part <= Pre; part++ {
+		if a[part] != b[part] {
+			return part
+		}
+	}
+	return -1
+}
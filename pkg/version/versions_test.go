@@ -53,3 +53,31 @@ func TestVersions_Less(t *testing.T) {
 		})
 	}
 }
+
+func TestCompare(t *testing.T) {
+	tt := []struct {
+		a, b         string
+		expected     int
+		expectedPart int
+	}{
+		{"1.2.3", "1.2.3", 0, -1},
+		{"1.2.3", "1.3.0", -1, Minor},
+		{"2.0.0", "1.9.9", 1, Major},
+		{"1.2.3", "1.2.4", -1, Patch},
+		{"1.2.3-RC.1", "1.2.3", -1, Pre},
+		{"1.2.3-RC.2", "1.2.3-RC.1", 1, Pre},
+		{"1.2.3-RC.2", "1.2.3-RC.2", 0, -1},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.a+" "+tc.b, func(t *testing.T) {
+			a, err := New(tc.a)
+			assert.NoError(t, err)
+			b, err := New(tc.b)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tc.expected, Compare(a, b))
+			assert.Equal(t, tc.expectedPart, DifferingPart(a, b))
+		})
+	}
+}
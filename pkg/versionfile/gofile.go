@@ -0,0 +1,115 @@
+package versionfile
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	"golang.org/x/mod/modfile"
+)
+
+// GoInfo are the release details of a generated Go version file.
+type GoInfo struct {
+	// Version is the released version, e.g. v1.2.3.
+	Version string
+	// PreviousVersion is the version before the release.
+	PreviousVersion string
+	// Commit is the hash of the commit the release was created from. The generated file is committed on top of it.
+	Commit string
+	// Date is the time of the release.
+	Date time.Time
+}
+
+var goFileTemplate = template.Must(template.New("gofile").Parse(`// Code generated by release; DO NOT EDIT.
+
+package {{.Package}}
+
+var (
+	// Version is the released version.
+	Version = {{printf "%q" .Version}}
+	// PreviousVersion is the version before the release.
+	PreviousVersion = {{printf "%q" .PreviousVersion}}
+	// Commit is the hash of the commit the release was created from.
+	Commit = {{printf "%q" .Commit}}
+	// Date is the time of the release in RFC 3339 format.
+	Date = {{printf "%q" .Date}}
+)
+`))
+
+// GoFile returns the formatted source of a Go file in the package, which declares the release details as string
+// variables. Unlike constants, the linker flags of LDFlags can still override them.
+func GoFile(pkg string, info GoInfo) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := goFileTemplate.Execute(&buf, map[string]string{
+		"Package":         pkg,
+		"Version":         info.Version,
+		"PreviousVersion": info.PreviousVersion,
+		"Commit":          info.Commit,
+		"Date":            info.Date.UTC().Format(time.RFC3339),
+	}); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+// LDFlags returns the linker flags, which inject the release details into the string variables of the package with
+// the import path instead.
+func LDFlags(importPath string, info GoInfo) string {
+	return fmt.Sprintf("-X %[1]v.Version=%[2]v -X %[1]v.PreviousVersion=%[3]v -X %[1]v.Commit=%[4]v -X %[1]v.Date=%[5]v",
+		importPath, info.Version, info.PreviousVersion, info.Commit, info.Date.UTC().Format(time.RFC3339))
+}
+
+// PackageName returns the package name of the Go files in the directory. It defaults to the directory name for
+// directories without Go files.
+func PackageName(dir string) string {
+	files, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.PackageClauseOnly)
+		if err == nil {
+			return f.Name.Name
+		}
+	}
+
+	name := regexp.MustCompile(`[^A-Za-z0-9_]`).ReplaceAllString(filepath.Base(dir), "")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		return "version"
+	}
+	return strings.ToLower(name)
+}
+
+// ImportPath returns the import path of the directory, which is derived from the go.mod file of the directory or of
+// a parent directory up to the root directory.
+func ImportPath(root, dir string) (string, error) {
+	for d := dir; ; d = filepath.Dir(d) {
+		data, err := ioutil.ReadFile(filepath.Join(d, "go.mod"))
+		if err == nil {
+			module := modfile.ModulePath(data)
+			if module == "" {
+				return "", fmt.Errorf("the go.mod file of %v has no module path", d)
+			}
+			rel, err := filepath.Rel(d, dir)
+			if err != nil {
+				return "", err
+			}
+			if rel == "." {
+				return module, nil
+			}
+			return module + "/" + filepath.ToSlash(rel), nil
+		}
+
+		if d == root || d == filepath.Dir(d) {
+			return "", fmt.Errorf("no go.mod file found for %v", dir)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package versionfile
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var goInfo = GoInfo{
+	Version:         "v1.3.0",
+	PreviousVersion: "v1.2.3",
+	Commit:          "3f4c1d8a",
+	Date:            time.Date(2020, 4, 12, 8, 15, 12, 0, time.UTC),
+}
+
+func TestGoFile(t *testing.T) {
+	data, err := GoFile("version", goInfo)
+	assert.NoError(t, err)
+	assert.Equal(t, `// Code generated by release; DO NOT EDIT.
+
+package version
+
+var (
+	// Version is the released version.
+	Version = "v1.3.0"
+	// PreviousVersion is the version before the release.
+	PreviousVersion = "v1.2.3"
+	// Commit is the hash of the commit the release was created from.
+	Commit = "3f4c1d8a"
+	// Date is the time of the release in RFC 3339 format.
+	Date = "2020-04-12T08:15:12Z"
+)
+`, string(data))
+
+	_, err = GoFile("no package", goInfo)
+	assert.Error(t, err)
+}
+
+func TestLDFlags(t *testing.T) {
+	assert.Equal(t, "-X example.com/m/internal/version.Version=v1.3.0 "+
+		"-X example.com/m/internal/version.PreviousVersion=v1.2.3 -X example.com/m/internal/version.Commit=3f4c1d8a "+
+		"-X example.com/m/internal/version.Date=2020-04-12T08:15:12Z", LDFlags("example.com/m/internal/version", goInfo))
+}
+
+func TestGoFile_LDFlags(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go isn't installed")
+	}
+	dir, err := ioutil.TempDir("", "release-gofile")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	data, err := GoFile("main", goInfo)
+	assert.NoError(t, err)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/m\n"), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "version.go"), data, 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "main.go"),
+		[]byte("package main\n\nfunc main() { println(Version, Commit) }\n"), 0644))
+
+	info := goInfo
+	info.Version, info.Commit = "v1.3.1", "9e0a7b2c"
+	cmd := exec.Command("go", "run", "-ldflags", LDFlags("main", info), ".")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	assert.NoError(t, err, string(out))
+	assert.Equal(t, "v1.3.1 9e0a7b2c\n", string(out), "the linker flags override the generated file")
+}
+
+func TestImportPath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "release-gofile")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	sub := filepath.Join(dir, "internal", "build-info")
+	assert.NoError(t, os.MkdirAll(sub, 0755))
+
+	_, err = ImportPath(dir, sub)
+	assert.Error(t, err)
+
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/m\n"), 0644))
+	path, err := ImportPath(dir, sub)
+	assert.NoError(t, err)
+	assert.Equal(t, "example.com/m/internal/build-info", path)
+
+	assert.Equal(t, "buildinfo", PackageName(sub))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(sub, "info.go"), []byte("package info\n"), 0644))
+	assert.Equal(t, "info", PackageName(sub))
+}
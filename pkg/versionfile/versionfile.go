@@ -0,0 +1,191 @@
+// Package versionfile replaces the version in project files like a VERSION file, a Go constant, the Chart.yaml of a
+// Helm chart or a package.json file.
+package versionfile
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Replacer replaces the old version with the new version in the content of a file. The versions are given without
+// prefix, e.g. 1.2.3. An old version with a v prefix is replaced by the new version with the prefix.
+type Replacer interface {
+	Replace(data []byte, old, new string) ([]byte, error)
+}
+
+// File is a file which stores the version.
+type File struct {
+	// Path is the path of the file relative to the repository root.
+	Path     string
+	Replacer Replacer
+}
+
+// New returns the file with the replacer of the regular expression or of the key path. The whole content is searched
+// for the old version if neither is given.
+func New(path, pattern, key string) (File, error) {
+	switch {
+	case pattern != "" && key != "":
+		return File{}, fmt.Errorf("%v: either a pattern or a key is expected", path)
+	case key != "":
+		return File{Path: path, Replacer: Key(strings.Split(key, "."))}, nil
+	default:
+		r, err := NewRegex(pattern)
+		if err != nil {
+			return File{}, fmt.Errorf("%v: %w", path, err)
+		}
+		return File{Path: path, Replacer: r}, nil
+	}
+}
+
+// Regex replaces the version in all matches of the pattern. The version is the first capture group or the whole match
+// if the pattern has no group. A nil pattern matches the old version.
+type Regex struct {
+	Pattern *regexp.Regexp
+}
+
+// NewRegex compiles the pattern, which may have at most one capture group.
+func NewRegex(pattern string) (Regex, error) {
+	if pattern == "" {
+		return Regex{}, nil
+	}
+
+	r, err := regexp.Compile(pattern)
+	if err != nil {
+		return Regex{}, fmt.Errorf("invalid pattern: %w", err)
+	}
+	if r.NumSubexp() > 1 {
+		return Regex{}, fmt.Errorf("the pattern %q has more than one capture group", pattern)
+	}
+	return Regex{Pattern: r}, nil
+}
+
+// Replace replaces the version of all matches. It returns an error if there is no match or a match doesn't contain
+// the old version.
+func (r Regex) Replace(data []byte, old, new string) ([]byte, error) {
+	pattern := r.Pattern
+	if pattern == nil {
+		pattern = regexp.MustCompile(`\bv?` + regexp.QuoteMeta(old) + `\b`)
+	}
+
+	matches := pattern.FindAllSubmatchIndex(data, -1)
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("the version %v wasn't found", old)
+	}
+
+	var spans [][2]int
+	for _, m := range matches {
+		span := [2]int{m[0], m[1]}
+		if len(m) > 2 {
+			span = [2]int{m[2], m[3]}
+		}
+		if span[0] < 0 {
+			continue
+		}
+		spans = append(spans, span)
+	}
+	return replace(data, spans, old, new)
+}
+
+// Key replaces the version of the key path in a YAML or JSON document, e.g. version or image.tag.
+type Key []string
+
+// Replace replaces the value of the key path. The document keeps its formatting, because only the value is replaced.
+// It returns an error if the value isn't the old version or the key can't be located unambiguously.
+func (k Key) Replace(data []byte, old, new string) ([]byte, error) {
+	var doc interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid document: %w", err)
+	}
+
+	for _, name := range k {
+		m, ok := doc.(map[interface{}]interface{})
+		if !ok {
+			return nil, fmt.Errorf("the key %v wasn't found", strings.Join(k, "."))
+		}
+		if doc, ok = m[name]; !ok {
+			return nil, fmt.Errorf("the key %v wasn't found", strings.Join(k, "."))
+		}
+	}
+	if value := fmt.Sprint(doc); strings.TrimPrefix(value, "v") != old {
+		return nil, fmt.Errorf("the key %v has the version %v instead of %v", strings.Join(k, "."), value, old)
+	}
+
+	// the value of the last key is replaced in place, which works for YAML and JSON alike
+	pattern := regexp.MustCompile(`(?:^|[\s{,])["']?` + regexp.QuoteMeta(k[len(k)-1]) + `["']?\s*:\s*["']?(v?` +
+		regexp.QuoteMeta(old) + `)\b`)
+	matches := pattern.FindAllSubmatchIndex(data, -1)
+	if len(matches) != 1 {
+		return nil, fmt.Errorf("the key %v with the version %v can't be located unambiguously", strings.Join(k, "."),
+			old)
+	}
+	return replace(data, [][2]int{{matches[0][2], matches[0][3]}}, old, new)
+}
+
+// replace replaces the old version at the spans of the data, which are in ascending order.
+func replace(data []byte, spans [][2]int, old, new string) ([]byte, error) {
+	var result = make([]byte, 0, len(data))
+	var last int
+	for _, span := range spans {
+		found := string(data[span[0]:span[1]])
+		if strings.TrimPrefix(found, "v") != old {
+			return nil, fmt.Errorf("found the version %v instead of %v", found, old)
+		}
+		replacement := new
+		if strings.HasPrefix(found, "v") {
+			replacement = "v" + new
+		}
+
+		result = append(append(result, data[last:span[0]]...), replacement...)
+		last = span[1]
+	}
+	return append(result, data[last:]...), nil
+}
+
+// Change is the new content of a file.
+type Change struct {
+	Path string
+	Data []byte
+	// Delete removes the file instead of writing the data.
+	Delete bool
+}
+
+// Replace replaces the old version with the new version in all files of the directory. No file is changed, if a file
+// doesn't contain the old version. The changes are written by Write.
+func Replace(dir string, files []File, old, new string) ([]Change, error) {
+	var changes = make([]Change, 0, len(files))
+	for _, f := range files {
+		data, err := ioutil.ReadFile(filepath.Join(dir, f.Path))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the version file: %w", err)
+		}
+
+		data, err = f.Replacer.Replace(data, old, new)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %w", f.Path, err)
+		}
+		changes = append(changes, Change{Path: f.Path, Data: data})
+	}
+	return changes, nil
+}
+
+// Write writes the changed files into the directory and removes the deleted files.
+func Write(dir string, changes []Change) error {
+	for _, c := range changes {
+		if c.Delete {
+			if err := os.Remove(filepath.Join(dir, c.Path)); err != nil {
+				return fmt.Errorf("failed to remove the file: %w", err)
+			}
+			continue
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, c.Path), c.Data, 0644); err != nil {
+			return fmt.Errorf("failed to write the version file: %w", err)
+		}
+	}
+	return nil
+}
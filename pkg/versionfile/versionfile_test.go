@@ -0,0 +1,82 @@
+package versionfile
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	tt := []struct {
+		name     string
+		pattern  string
+		key      string
+		data     string
+		expected string
+		fails    bool
+	}{
+		{"VERSION", "", "", "1.4.2\n", "1.4.3\n", false},
+		{"VERSION", "", "", "v1.4.2\n", "v1.4.3\n", false},
+		{"VERSION", "", "", "11.4.2\n", "", true},
+		{"VERSION", "", "", "1.4.1\n", "", true},
+		{"main.go", `Version = "(.*)"`, "", "package main\n\nconst Version = \"v1.4.2\"\n",
+			"package main\n\nconst Version = \"v1.4.3\"\n", false},
+		{"main.go", `Version = "(.*)"`, "", "package main\n\nconst Version = \"v1.4.1\"\n", "", true},
+		{"main.go", `Version = "(.*)"`, "", "package main\n", "", true},
+		{"Chart.yaml", "", "version", "apiVersion: v2\nname: app\nversion: 1.4.2\nappVersion: \"1.4.2\"\n",
+			"apiVersion: v2\nname: app\nversion: 1.4.3\nappVersion: \"1.4.2\"\n", false},
+		{"Chart.yaml", "", "appVersion", "apiVersion: v2\nname: app\nversion: 1.4.2\nappVersion: \"1.4.2\"\n",
+			"apiVersion: v2\nname: app\nversion: 1.4.2\nappVersion: \"1.4.3\"\n", false},
+		{"values.yaml", "", "image.tag", "image:\n  repository: app\n  tag: v1.4.2\n",
+			"image:\n  repository: app\n  tag: v1.4.3\n", false},
+		{"values.yaml", "", "image.tag", "image:\n  tag: v1.4.1\n", "", true},
+		{"values.yaml", "", "image.name", "image:\n  tag: v1.4.2\n", "", true},
+		{"package.json", "", "version", "{\n  \"name\": \"app\",\n  \"version\": \"1.4.2\"\n}\n",
+			"{\n  \"name\": \"app\",\n  \"version\": \"1.4.3\"\n}\n", false},
+		{"package.json", "", "version", "{\"version\": \"1.4.2\", \"deps\": {\"version\": \"1.4.2\"}}", "", true},
+		{"both", "(.*)", "version", "", "", true},
+		{"groups", "(a)(b)", "", "", "", true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := New(tc.name, tc.pattern, tc.key)
+			if err == nil {
+				var data []byte
+				data, err = f.Replacer.Replace([]byte(tc.data), "1.4.2", "1.4.3")
+				if err == nil {
+					assert.Equal(t, tc.expected, string(data))
+				}
+			}
+			if tc.fails {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestReplace(t *testing.T) {
+	dir, err := ioutil.TempDir("", "release-versionfile")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "VERSION"), []byte("1.4.2\n"), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "Chart.yaml"), []byte("version: 1.4.1\n"), 0644))
+	files := []File{{Path: "VERSION", Replacer: Regex{}}, {Path: "Chart.yaml", Replacer: Key{"version"}}}
+
+	_, err = Replace(dir, files, "1.4.2", "1.4.3")
+	assert.Error(t, err)
+
+	changes, err := Replace(dir, files[:1], "1.4.2", "1.4.3")
+	assert.NoError(t, err)
+	assert.NoError(t, Write(dir, changes))
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "VERSION"))
+	assert.NoError(t, err)
+	assert.Equal(t, "1.4.3\n", string(data))
+}